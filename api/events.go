@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+const (
+	_ChangesPollInterval = 500 * time.Millisecond
+	_ChangesHeartbeat    = 15 * time.Second
+	_ChangesBatchSize    = 100
+)
+
+func (h *Handler) streamChanges(ctx context.Context, w io.Writer, flush func() error, bucketName string, since int64) error {
+	if err := flush(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(_ChangesPollInterval)
+	defer ticker.Stop()
+
+	lastWrite := time.Now()
+
+	for {
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		changes, err := h.service.Changes(c, bucketName, since, _ChangesBatchSize)
+		cancel()
+
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			if err := writeEvent(w, change); err != nil {
+				return err
+			}
+
+			since = change.Sequence
+		}
+
+		if len(changes) == 0 && time.Since(lastWrite) >= _ChangesHeartbeat {
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return err
+			}
+		}
+
+		if len(changes) > 0 || time.Since(lastWrite) >= _ChangesHeartbeat {
+			if err := flush(); err != nil {
+				return err
+			}
+
+			lastWrite = time.Now()
+		}
+
+		if len(changes) == _ChangesBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeEvent(w io.Writer, change model.Change) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.Sequence, change.Type, data)
+	return err
+}
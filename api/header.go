@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/httprouter"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+const (
+	_JSONContentType       = "application/json"
+	_MergePatchContentType = "application/merge-patch+json"
+)
+
+// numbers are decoded as json.Number, so integers keep their precision
+func decodeBody(req *http.Request, target any) error {
+	decoder := json.NewDecoder(req.Body)
+	decoder.UseNumber()
+
+	return decoder.Decode(target)
+}
+
+func readPrecondition(req *http.Request) (model.Precondition, error) {
+	var precondition model.Precondition
+
+	if ifMatch := req.Header.Get("If-Match"); len(ifMatch) > 0 {
+		if ifMatch == "*" {
+			precondition.MustExist = true
+		} else {
+			versions, err := parseETags(ifMatch)
+			if err != nil {
+				return precondition, apperror.InvalidPrecondition.WithCause(err, "If-Match: "+ifMatch)
+			}
+
+			precondition.Versions = versions
+		}
+	}
+
+	if ifNoneMatch := req.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		if ifNoneMatch != "*" {
+			return precondition, apperror.InvalidPrecondition.New("If-None-Match: " + ifNoneMatch)
+		}
+
+		precondition.MustNotExist = true
+	}
+
+	return precondition, nil
+}
+
+// checkMergePatch accepts JSON merge patches, sent as application/merge-patch+json or application/json
+func checkMergePatch(req *http.Request) error {
+	mediaType, _, _ := strings.Cut(req.Header.Get("Content-Type"), ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+
+	switch mediaType {
+	case "", _JSONContentType, _MergePatchContentType:
+		return nil
+	}
+
+	return apperror.UnsupportedMediaType.New(mediaType)
+}
+
+func readTTL(req *http.Request) (time.Duration, error) {
+	value := req.URL.Query().Get("ttl")
+	if len(value) == 0 {
+		value = req.Header.Get("X-TTL")
+	}
+
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return 0, apperror.InvalidTTL.New(value)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func readAsOf(req *http.Request) (*time.Time, error) {
+	value := req.URL.Query().Get("asOf")
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperror.InvalidTimestamp.WithCause(err, value)
+	}
+
+	return &asOf, nil
+}
+
+func readLastEventID(req *http.Request) (int64, bool, error) {
+	value := req.Header.Get("Last-Event-ID")
+	if len(value) == 0 {
+		return 0, false, nil
+	}
+
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, false, apperror.InvalidEventID.New(value)
+	}
+
+	return seq, true, nil
+}
+
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseETags reads a list of strong ETags, as If-Match only uses the strong comparison
+func parseETags(value string) ([]int64, error) {
+	var versions []int64
+
+	for _, tag := range strings.Split(value, ",") {
+		version, err := parseETag(strings.TrimSpace(tag))
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func parseETag(value string) (int64, error) {
+	if strings.HasPrefix(value, "W/") {
+		return 0, errors.New("weak ETags can't be used on If-Match")
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if version < 1 {
+		return 0, errors.New("versions start at 1")
+	}
+
+	return version, nil
+}
+
+func setNextLink(ctx *httprouter.Context, next string) {
+	if len(next) == 0 {
+		return
+	}
+
+	nextURL := *ctx.Request.URL
+	parameters := nextURL.Query()
+	parameters.Set(model.CursorParameter, next)
+	nextURL.RawQuery = parameters.Encode()
+
+	ctx.SetHeader("Link", "<"+nextURL.RequestURI()+">; rel=\"next\"")
+}
+
+// readAttachment returns a reader of the request body, it fails when the body is larger than the maximum size of attachments
+func readAttachment(ctx *httprouter.Context) io.Reader {
+	return &attachmentBody{body: http.MaxBytesReader(ctx.Writer, ctx.Request.Body, model.MaxAttachmentSize)}
+}
+
+type attachmentBody struct {
+	body io.Reader
+}
+
+func (b *attachmentBody) Read(p []byte) (int, error) {
+	size, err := b.body.Read(p)
+	if err == nil || err == io.EOF {
+		return size, err
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return size, apperror.AttachmentTooLarge.New(model.MaxAttachmentSize)
+	}
+
+	return size, apperror.BadRequestPaylod.WithCause(err)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestReadPrecondition(t *testing.T) {
+	cases := map[string][]int64{
+		`"3"`:      {3},
+		`"3", "4"`: {3, 4},
+		`"3","4"`:  {3, 4},
+	}
+
+	for ifMatch, versions := range cases {
+		req := httptest.NewRequest("PUT", "/v1/buckets/people/keys/k1", nil)
+		req.Header.Set("If-Match", ifMatch)
+
+		precondition, err := readPrecondition(req)
+		if err != nil {
+			t.Errorf("If-Match %v: %v", ifMatch, err)
+			continue
+		}
+
+		if !slices.Equal(precondition.Versions, versions) {
+			t.Errorf("If-Match %v read as %v, expected %v", ifMatch, precondition.Versions, versions)
+		}
+	}
+}
+
+// If-Match uses the strong comparison and versions start at 1
+func TestReadInvalidPrecondition(t *testing.T) {
+	headers := []map[string]string{
+		{"If-Match": `W/"3"`},
+		{"If-Match": `"3", W/"4"`},
+		{"If-Match": `"0"`},
+		{"If-Match": `3`},
+		{"If-None-Match": `"3"`},
+	}
+
+	for _, header := range headers {
+		req := httptest.NewRequest("PUT", "/v1/buckets/people/keys/k1", nil)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+
+		if _, err := readPrecondition(req); err == nil {
+			t.Errorf("expected %v to be rejected", header)
+		}
+	}
+}
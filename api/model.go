@@ -6,15 +6,22 @@ import (
 )
 
 type externalBucket struct {
-	Name   string        `json:"name"`
-	Schema []model.Field `json:"schema"`
+	Name       string            `json:"name"`
+	Schema     []model.Field     `json:"schema"`
+	JSONSchema *model.JSONSchema `json:"json-schema,omitempty"`
+	model.BucketOptions
 }
 
 func createExternalBucket(bucket repo.Bucket) *externalBucket {
 	rep := externalBucket{
-		Name:   bucket.Name(),
-		Schema: bucket.Schema(),
+		Name:          bucket.Name(),
+		Schema:        bucket.Schema(),
+		BucketOptions: bucket.Options(),
 	}
 
 	return &rep
 }
+
+type operationsRequest struct {
+	Operations []model.Operation `json:"operations"`
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/jjmrocha/oblivion/httprouter"
+	"github.com/jjmrocha/oblivion/model"
+	"github.com/jjmrocha/oblivion/repo"
+)
+
+const (
+	_OpenAPIVersion     = "3.1.0"
+	_APIVersion         = "1.0.0"
+	_ProblemSchemaName  = "Problem"
+	_BucketSchemaPrefix = "Bucket_"
+	_ProblemContentType = "application/problem+json"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// openAPIPathItem maps the lower case methods of a path to their operations
+type openAPIPathItem map[string]openAPIOperation
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string           `json:"name"`
+	In       string           `json:"in"`
+	Required bool             `json:"required,omitempty"`
+	Schema   model.JSONSchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                    `json:"required"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema model.JSONSchema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]model.JSONSchema `json:"schemas"`
+}
+
+// newOpenAPIDocument describes the routes of the router, the routes using the values of a bucket
+// are also described for each bucket, with the bucket schema as component
+func newOpenAPIDocument(routes []httprouter.Route, buckets []repo.Bucket) openAPIDocument {
+	document := openAPIDocument{
+		OpenAPI: _OpenAPIVersion,
+		Info:    openAPIInfo{Title: "Oblivion", Version: _APIVersion},
+		Paths:   make(map[string]openAPIPathItem),
+		Components: openAPIComponents{
+			Schemas: map[string]model.JSONSchema{_ProblemSchemaName: problemSchema()},
+		},
+	}
+
+	for _, route := range routes {
+		document.addOperation(route.Method, route.Path, newOpenAPIOperation(route.Method, route.Path))
+	}
+
+	for _, bucket := range buckets {
+		schema := model.NewJSONSchema(bucket.Name(), bucket.Schema(), bucket.Options())
+		schema.Dialect = ""
+		document.Components.Schemas[bucketSchemaName(bucket.Name())] = schema
+
+		for _, route := range routes {
+			path := strings.Replace(route.Path, "{bucket}", bucket.Name(), 1)
+			operation := newOpenAPIOperation(route.Method, path)
+			operation.OperationID = bucketOperationID(route, bucket.Name())
+
+			if bucketOperation(route, bucket, &operation) {
+				document.addOperation(route.Method, path, operation)
+			}
+		}
+	}
+
+	return document
+}
+
+func (d *openAPIDocument) addOperation(method string, path string, operation openAPIOperation) {
+	item, found := d.Paths[path]
+	if !found {
+		item = make(openAPIPathItem)
+		d.Paths[path] = item
+	}
+
+	item[strings.ToLower(method)] = operation
+}
+
+// newOpenAPIOperation describes a route without knowing its payloads, errors are always problem details
+func newOpenAPIOperation(method string, path string) openAPIOperation {
+	operation := openAPIOperation{
+		OperationID: operationID(method, path),
+		Responses: map[string]openAPIResponse{
+			"2XX":     {Description: "Success"},
+			"default": jsonResponse("Error", _ProblemContentType, schemaRef(_ProblemSchemaName)),
+		},
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if name, found := strings.CutPrefix(segment, "{"); found {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name:     strings.TrimSuffix(name, "}"),
+				In:       "path",
+				Required: true,
+				Schema:   model.JSONSchema{Type: "string"},
+			})
+		}
+	}
+
+	return operation
+}
+
+// bucketOperation completes the operations on keys and searches with the schema of the bucket,
+// returns false for the routes that aren't described for each bucket
+func bucketOperation(route httprouter.Route, bucket repo.Bucket, operation *openAPIOperation) bool {
+	value := schemaRef(bucketSchemaName(bucket.Name()))
+	key := model.JSONSchema{Type: "string"}
+	entry := model.JSONSchema{
+		Type: "object",
+		Properties: model.JSONProperties{
+			{Name: "key", Schema: key},
+			{Name: "version", Schema: model.JSONSchema{Type: "integer"}},
+			{Name: "value", Schema: value},
+		},
+		Required: []string{"key", "version", "value"},
+	}
+
+	switch route.Method + " " + route.Path {
+	case "GET /v1/buckets/{bucket}/keys/{key}":
+		operation.Responses["200"] = jsonResponse("Value of the key", _JSONContentType, value)
+	case "PUT /v1/buckets/{bucket}/keys/{key}":
+		operation.RequestBody = jsonRequestBody(_JSONContentType, value)
+		operation.Responses["204"] = openAPIResponse{Description: "Value stored"}
+	case "PATCH /v1/buckets/{bucket}/keys/{key}":
+		operation.RequestBody = jsonRequestBody(_MergePatchContentType, model.JSONSchema{Type: "object"})
+		operation.Responses["204"] = openAPIResponse{Description: "Value updated"}
+	case "DELETE /v1/buckets/{bucket}/keys/{key}":
+		operation.Responses["204"] = openAPIResponse{Description: "Key deleted"}
+	case "GET /v1/buckets/{bucket}/keys":
+		operation.Parameters = append(operation.Parameters, queryParameters(bucket.Schema())...)
+		operation.Responses["200"] = jsonResponse("Keys found", _JSONContentType, pageOrList(key))
+	case "GET /v1/buckets/{bucket}/objects":
+		operation.Parameters = append(operation.Parameters, queryParameters(bucket.Schema())...)
+		operation.Responses["200"] = jsonResponse("Objects found", _JSONContentType, pageOrList(entry))
+	case "POST /v1/buckets/{bucket}/query":
+		operation.RequestBody = jsonRequestBody(_JSONContentType, model.JSONSchema{Type: "object"})
+		operation.Responses["200"] = jsonResponse("Keys or objects found, as requested by return", _JSONContentType, model.JSONSchema{
+			OneOf: []model.JSONSchema{page(key), page(entry)},
+		})
+	default:
+		return false
+	}
+
+	operation.Tags = []string{bucket.Name()}
+	delete(operation.Responses, "2XX")
+
+	return true
+}
+
+// queryParameters are the reserved query parameters, the full-text search when the schema has searchable fields
+// and the criteria on the scalar fields of the schema
+func queryParameters(schema []model.Field) []openAPIParameter {
+	text := model.JSONSchema{Type: "string"}
+	parameters := []openAPIParameter{
+		{Name: model.FieldsParameter, In: "query", Schema: text},
+		{Name: model.SortParameter, In: "query", Schema: text},
+		{Name: model.LimitParameter, In: "query", Schema: model.JSONSchema{Type: "integer"}},
+		{Name: model.CursorParameter, In: "query", Schema: text},
+	}
+
+	if len(model.SearchableFields(schema)) > 0 {
+		parameters = append(parameters, openAPIParameter{Name: model.SearchParameter, In: "query", Schema: text})
+	}
+
+	for _, field := range schema {
+		if field.Type.IsJSON() || field.Type == model.BinaryDataType {
+			continue
+		}
+
+		fieldSchema := field.JSONSchema()
+		parameters = append(parameters, openAPIParameter{
+			Name:   field.Name,
+			In:     "query",
+			Schema: model.JSONSchema{Type: fieldSchema.Type, Format: fieldSchema.Format, Enum: fieldSchema.Enum},
+		})
+	}
+
+	return parameters
+}
+
+// operationID joins the method and the path segments in camel case, e.g. getBucketsBucketKeysKey
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		if segment == "v1" {
+			continue
+		}
+
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '.' || r == '_' || r == '-' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	return id
+}
+
+// bucketOperationID adds the bucket name, as is, to the id of the route, the ids of the routes have no underscores,
+// so the ids are unique for all buckets and don't collide with the ids of the routes
+func bucketOperationID(route httprouter.Route, bucket string) string {
+	return operationID(route.Method, route.Path) + "_" + bucket
+}
+
+func jsonResponse(description string, contentType string, schema model.JSONSchema) openAPIResponse {
+	return openAPIResponse{
+		Description: description,
+		Content:     map[string]openAPIMedia{contentType: {Schema: schema}},
+	}
+}
+
+func jsonRequestBody(contentType string, schema model.JSONSchema) *openAPIRequestBody {
+	body := openAPIRequestBody{
+		Required: true,
+		Content:  map[string]openAPIMedia{contentType: {Schema: schema}},
+	}
+
+	return &body
+}
+
+// bucketSchemaName prefixes the component schemas of the buckets, so a bucket can't replace the Problem schema
+func bucketSchemaName(bucket string) string {
+	return _BucketSchemaPrefix + bucket
+}
+
+func schemaRef(name string) model.JSONSchema {
+	return model.JSONSchema{Ref: "#/components/schemas/" + name}
+}
+
+func page(items model.JSONSchema) model.JSONSchema {
+	return model.JSONSchema{
+		Type: "object",
+		Properties: model.JSONProperties{
+			{Name: "items", Schema: model.JSONSchema{Type: "array", Items: &items}},
+			{Name: "next", Schema: model.JSONSchema{Type: "string"}},
+		},
+		Required: []string{"items"},
+	}
+}
+
+// pageOrList is the result of searches, only paginated searches return pages
+func pageOrList(items model.JSONSchema) model.JSONSchema {
+	return model.JSONSchema{
+		OneOf: []model.JSONSchema{{Type: "array", Items: &items}, page(items)},
+	}
+}
+
+func problemSchema() model.JSONSchema {
+	text := model.JSONSchema{Type: "string"}
+	violation := model.JSONSchema{
+		Type: "object",
+		Properties: model.JSONProperties{
+			{Name: "field", Schema: text},
+			{Name: "code", Schema: text},
+			{Name: "message", Schema: text},
+		},
+	}
+
+	return model.JSONSchema{
+		Type: "object",
+		Properties: model.JSONProperties{
+			{Name: "type", Schema: text},
+			{Name: "title", Schema: text},
+			{Name: "status", Schema: model.JSONSchema{Type: "integer"}},
+			{Name: "detail", Schema: text},
+			{Name: "instance", Schema: text},
+			{Name: "code", Schema: text},
+			{Name: "details", Schema: model.JSONSchema{}},
+			{Name: "errors", Schema: model.JSONSchema{Type: "array", Items: &violation}},
+		},
+		Required: []string{"type", "title", "status", "detail"},
+	}
+}
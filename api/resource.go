@@ -2,7 +2,7 @@ package api
 
 import (
 	"context"
-	"encoding/json"
+	"io"
 	"time"
 
 	"github.com/jjmrocha/oblivion/apperror"
@@ -27,6 +27,10 @@ func NewHandler(bucketService *bucket.BucketService) *Handler {
 func (h *Handler) SetRoutes(router *httprouter.Router) {
 	setBucketRoutes(router, h)
 	setKeyRoutes(router, h)
+	setAttachmentRoutes(router, h)
+	setChangeRoutes(router, h)
+	setTransactionRoutes(router, h)
+	setOpenAPIRoutes(router, h)
 }
 
 func setBucketRoutes(router *httprouter.Router, h *Handler) {
@@ -45,7 +49,7 @@ func setBucketRoutes(router *httprouter.Router, h *Handler) {
 	router.POST("/v1/buckets", func(ctx *httprouter.Context) (*httprouter.Response, error) {
 		var request externalBucket
 
-		err := json.NewDecoder(ctx.Request.Body).Decode(&request)
+		err := decodeBody(ctx.Request, &request)
 		if err != nil {
 			return nil, apperror.BadRequestPaylod.WithCause(err)
 		}
@@ -54,14 +58,41 @@ func setBucketRoutes(router *httprouter.Router, h *Handler) {
 			return nil, err
 		}
 
-		if err := valid.Schema(request.Schema); err != nil {
+		// the schema can also be defined using JSON Schema
+		if request.JSONSchema != nil {
+			if len(request.Schema) > 0 {
+				return nil, apperror.InvalidJSONSchema.New("#", "schema and json-schema can't be used together")
+			}
+
+			if err := valid.JSONSchema(*request.JSONSchema, request.BucketOptions); err != nil {
+				return nil, err
+			}
+
+			request.Schema = request.JSONSchema.Fields()
+			if request.JSONSchema.Mode() == model.DocumentMode {
+				request.Mode = model.DocumentMode
+			}
+
+			request.JSONSchema = nil
+		}
+
+		if err := valid.Schema(request.Schema, request.BucketOptions); err != nil {
+			return nil, err
+		}
+
+		// document buckets can be created without schema
+		if request.Schema == nil {
+			request.Schema = []model.Field{}
+		}
+
+		if err := valid.Options(request.BucketOptions, request.Schema); err != nil {
 			return nil, err
 		}
 
 		c, cancel := context.WithTimeout(ctx, 3*time.Second)
 		defer cancel()
 
-		bucket, err := h.service.CreateBucket(c, request.Name, request.Schema)
+		bucket, err := h.service.CreateBucket(c, request.Name, request.Schema, request.BucketOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -91,6 +122,26 @@ func setBucketRoutes(router *httprouter.Router, h *Handler) {
 		return ctx.OK(response)
 	})
 
+	router.GET("/v1/buckets/{bucket}/schema.json", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		bucket, err := h.service.GetBucket(c, bucketName)
+		if err != nil {
+			return nil, err
+		}
+
+		response := model.NewJSONSchema(bucket.Name(), bucket.Schema(), bucket.Options())
+
+		return ctx.OK(response)
+	})
+
 	router.DELETE("/v1/buckets/{bucket}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
 		bucketName := ctx.Request.PathValue("bucket")
 
@@ -108,6 +159,33 @@ func setBucketRoutes(router *httprouter.Router, h *Handler) {
 
 		return ctx.NoContent()
 	})
+
+	router.PATCH("/v1/buckets/{bucket}/schema", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		var request model.SchemaChange
+
+		err := decodeBody(ctx.Request, &request)
+		if err != nil {
+			return nil, apperror.BadRequestPaylod.WithCause(err)
+		}
+
+		c, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		bucket, err := h.service.UpdateSchema(c, bucketName, request)
+		if err != nil {
+			return nil, err
+		}
+
+		response := createExternalBucket(bucket)
+
+		return ctx.OK(response)
+	})
 }
 
 func setKeyRoutes(router *httprouter.Router, h *Handler) {
@@ -123,17 +201,81 @@ func setKeyRoutes(router *httprouter.Router, h *Handler) {
 			return nil, err
 		}
 
+		asOf, err := readAsOf(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
 		c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 		defer cancel()
 
-		value, err := h.service.Value(c, bucketName, key)
+		var value model.Object
+		var version int64
+
+		if asOf != nil {
+			value, version, err = h.service.ValueAt(c, bucketName, key, *asOf)
+		} else {
+			value, version, err = h.service.Value(c, bucketName, key)
+		}
+
 		if err != nil {
 			return nil, err
 		}
 
+		ctx.SetHeader("ETag", etag(version))
+
 		return ctx.OK(value)
 	})
 
+	router.GET("/v1/buckets/{bucket}/by/{field}/{value}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		fieldName := ctx.Request.PathValue("field")
+		value := ctx.Request.PathValue("value")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		if err := valid.FieldName(fieldName); err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		entry, err := h.service.ValueBy(c, bucketName, fieldName, value)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.SetHeader("ETag", etag(entry.Version))
+
+		return ctx.OK(entry)
+	})
+
+	router.GET("/v1/buckets/{bucket}/keys/{key}/history", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		key := ctx.Request.PathValue("key")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		if err := valid.Key(key); err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+
+		revisions, err := h.service.History(c, bucketName, key)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.OK(revisions)
+	})
+
 	router.PUT("/v1/buckets/{bucket}/keys/{key}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
 		bucketName := ctx.Request.PathValue("bucket")
 		key := ctx.Request.PathValue("key")
@@ -146,9 +288,19 @@ func setKeyRoutes(router *httprouter.Router, h *Handler) {
 			return nil, err
 		}
 
+		precondition, err := readPrecondition(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		ttl, err := readTTL(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
 		var value model.Object
 
-		err := json.NewDecoder(ctx.Request.Body).Decode(&value)
+		err = decodeBody(ctx.Request, &value)
 		if err != nil {
 			return nil, apperror.BadRequestPaylod.WithCause(err)
 		}
@@ -156,11 +308,60 @@ func setKeyRoutes(router *httprouter.Router, h *Handler) {
 		c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 		defer cancel()
 
-		err = h.service.SetValue(c, bucketName, key, value)
+		version, err := h.service.SetValue(c, bucketName, key, value, precondition, ttl)
 		if err != nil {
 			return nil, err
 		}
 
+		ctx.SetHeader("ETag", etag(version))
+
+		return ctx.NoContent()
+	})
+
+	router.PATCH("/v1/buckets/{bucket}/keys/{key}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		key := ctx.Request.PathValue("key")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		if err := valid.Key(key); err != nil {
+			return nil, err
+		}
+
+		if err := checkMergePatch(ctx.Request); err != nil {
+			ctx.SetHeader("Accept-Patch", _MergePatchContentType)
+			return nil, err
+		}
+
+		precondition, err := readPrecondition(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		ttl, err := readTTL(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		var patch model.Object
+
+		err = decodeBody(ctx.Request, &patch)
+		if err != nil {
+			return nil, apperror.BadRequestPaylod.WithCause(err)
+		}
+
+		c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		version, err := h.service.PatchValue(c, bucketName, key, patch, precondition, ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.SetHeader("ETag", etag(version))
+
 		return ctx.NoContent()
 	})
 
@@ -176,10 +377,15 @@ func setKeyRoutes(router *httprouter.Router, h *Handler) {
 			return nil, err
 		}
 
+		precondition, err := readPrecondition(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
 		c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 		defer cancel()
 
-		err := h.service.DeleteValue(c, bucketName, key)
+		err = h.service.DeleteValue(c, bucketName, key, precondition)
 		if err != nil {
 			return nil, err
 		}
@@ -187,9 +393,34 @@ func setKeyRoutes(router *httprouter.Router, h *Handler) {
 		return ctx.NoContent()
 	})
 
+	router.POST("/v1/buckets/{bucket}/batch", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		var request operationsRequest
+
+		err := decodeBody(ctx.Request, &request)
+		if err != nil {
+			return nil, apperror.BadRequestPaylod.WithCause(err)
+		}
+
+		c, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+
+		results, err := h.service.Batch(c, bucketName, request.Operations)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.OK(results)
+	})
+
 	router.GET("/v1/buckets/{bucket}/keys", func(ctx *httprouter.Context) (*httprouter.Response, error) {
 		bucketName := ctx.Request.PathValue("bucket")
-		criteria := ctx.Request.URL.Query()
+		parameters := ctx.Request.URL.Query()
 
 		if err := valid.BucketName(bucketName); err != nil {
 			return nil, err
@@ -198,11 +429,259 @@ func setKeyRoutes(router *httprouter.Router, h *Handler) {
 		c, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 		defer cancel()
 
-		keys, err := h.service.FindKeys(c, bucketName, criteria)
+		keys, err := h.service.FindKeys(c, bucketName, parameters)
 		if err != nil {
 			return nil, err
 		}
 
+		if !model.Paginated(parameters) {
+			return ctx.OK(keys.Items)
+		}
+
+		setNextLink(ctx, keys.Next)
+
 		return ctx.OK(keys)
 	})
+
+	router.GET("/v1/buckets/{bucket}/objects", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		parameters := ctx.Request.URL.Query()
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		entries, err := h.service.FindObjects(c, bucketName, parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		if !model.Paginated(parameters) {
+			return ctx.OK(entries.Items)
+		}
+
+		setNextLink(ctx, entries.Next)
+
+		return ctx.OK(entries)
+	})
+
+	router.POST("/v1/buckets/{bucket}/query", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		var request model.SearchRequest
+
+		err := decodeBody(ctx.Request, &request)
+		if err != nil {
+			return nil, apperror.BadRequestPaylod.WithCause(err)
+		}
+
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		if request.Return == model.ObjectsResult {
+			entries, err := h.service.QueryObjects(c, bucketName, request)
+			if err != nil {
+				return nil, err
+			}
+
+			return ctx.OK(entries)
+		}
+
+		keys, err := h.service.QueryKeys(c, bucketName, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.OK(keys)
+	})
+
+	router.GET("/v1/buckets/{bucket}/aggregate", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		parameters := ctx.Request.URL.Query()
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		results, err := h.service.Aggregate(c, bucketName, parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.OK(results)
+	})
+}
+
+func setAttachmentRoutes(router *httprouter.Router, h *Handler) {
+	router.PUT("/v1/buckets/{bucket}/keys/{key}/attachments/{name}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		key := ctx.Request.PathValue("key")
+		name := ctx.Request.PathValue("name")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		if err := valid.Key(key); err != nil {
+			return nil, err
+		}
+
+		if err := valid.AttachmentName(name); err != nil {
+			return nil, err
+		}
+
+		attachment := model.Attachment{
+			Name:        name,
+			ContentType: ctx.Request.Header.Get("Content-Type"),
+			Content:     readAttachment(ctx),
+		}
+
+		c, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		err := h.service.SetAttachment(c, bucketName, key, attachment)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.NoContent()
+	})
+
+	router.GET("/v1/buckets/{bucket}/keys/{key}/attachments/{name}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		key := ctx.Request.PathValue("key")
+		name := ctx.Request.PathValue("name")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		if err := valid.Key(key); err != nil {
+			return nil, err
+		}
+
+		if err := valid.AttachmentName(name); err != nil {
+			return nil, err
+		}
+
+		// the content is read while it's written, so the parts are read with the request context
+		attachment, err := h.service.Attachment(ctx, bucketName, key, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.Stream(attachment.ContentType, func(w io.Writer, flush func() error) error {
+			_, err := io.Copy(w, attachment.Content)
+			return err
+		})
+	})
+
+	router.DELETE("/v1/buckets/{bucket}/keys/{key}/attachments/{name}", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+		key := ctx.Request.PathValue("key")
+		name := ctx.Request.PathValue("name")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		if err := valid.Key(key); err != nil {
+			return nil, err
+		}
+
+		if err := valid.AttachmentName(name); err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		err := h.service.DeleteAttachment(c, bucketName, key, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.NoContent()
+	})
+}
+
+func setChangeRoutes(router *httprouter.Router, h *Handler) {
+	router.GET("/v1/buckets/{bucket}/changes", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		bucketName := ctx.Request.PathValue("bucket")
+
+		if err := valid.BucketName(bucketName); err != nil {
+			return nil, err
+		}
+
+		since, found, err := readLastEventID(ctx.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		if found {
+			_, err = h.service.GetBucket(c, bucketName)
+		} else {
+			since, err = h.service.LastChange(c, bucketName)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ctx.SetHeader("Cache-Control", "no-cache")
+
+		return ctx.Stream("text/event-stream", func(w io.Writer, flush func() error) error {
+			return h.streamChanges(ctx, w, flush, bucketName, since)
+		})
+	})
+}
+
+func setTransactionRoutes(router *httprouter.Router, h *Handler) {
+	router.POST("/v1/transactions", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		var request operationsRequest
+
+		err := decodeBody(ctx.Request, &request)
+		if err != nil {
+			return nil, apperror.BadRequestPaylod.WithCause(err)
+		}
+
+		c, cancel := context.WithTimeout(ctx, 3*time.Second)
+		defer cancel()
+
+		results, err := h.service.Transaction(c, request.Operations)
+		if err != nil {
+			return nil, err
+		}
+
+		return ctx.OK(results)
+	})
+}
+
+func setOpenAPIRoutes(router *httprouter.Router, h *Handler) {
+	router.GET("/v1/openapi.json", func(ctx *httprouter.Context) (*httprouter.Response, error) {
+		c, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		buckets, err := h.service.Buckets(c)
+		if err != nil {
+			return nil, err
+		}
+
+		response := newOpenAPIDocument(router.Routes(), buckets)
+
+		return ctx.OK(response)
+	})
 }
@@ -8,6 +8,8 @@ type Error struct {
 	ErrorType   ErrorType
 	Description string
 	Cause       error
+	Details     any
+	Violations  []Violation
 }
 
 func (e *Error) String() string {
@@ -23,3 +25,23 @@ func (e *Error) Error() string {
 
 	return errorMsg
 }
+
+func Describe(err error) string {
+	if appErr, ok := err.(*Error); ok {
+		return appErr.String()
+	}
+
+	return err.Error()
+}
+
+func WithDetails(err error, details any) error {
+	appErr, ok := err.(*Error)
+	if !ok {
+		appErr = UnexpectedError.WithCause(err).(*Error)
+	}
+
+	withDetails := *appErr
+	withDetails.Details = details
+
+	return &withDetails
+}
@@ -28,63 +28,351 @@ const (
 	InvalidFieldType
 	// Gneric
 	UnexpectedError
+	// New error types must be added at the end, the error code is the declaration order
+	EmptySchemaChange
+	FieldAlreadyExists
+	IncompatibleSchemaChange
+	PreconditionFailed
+	InvalidPrecondition
+	InvalidOperation
+	ConditionFailed
+	InvalidCondition
+	InvalidTTL
+	InvalidTimestamp
+	HistoryNotEnabled
+	InvalidEventID
+	InvalidCriteria
+	InvalidSort
+	InvalidLimit
+	InvalidCursor
+	InvalidAggregate
+	FieldNotSearchable
+	SearchNotEnabled
+	SearchNotSupported
+	InvalidFilter
+	InvalidResultType
+	UniqueViolation
+	InvalidIndex
+	FieldIndexed
+	FieldNotUnique
+	InvalidEnumValues
+	InvalidFieldDefinition
+	ConstraintViolation
+	InvalidConstraint
+	AttachmentNotFound
+	InvalidAttachmentName
+	AttachmentTooLarge
+	InvalidBucketMode
+	InvalidJSONSchema
+	UnsupportedSchemaKeyword
+	UnsupportedMediaType
 )
 
 type config struct {
+	id         string
+	title      string
 	statusCode int
 	template   string
 }
 
 var errorTypes = map[ErrorType]config{
 	BucketAlreadyExits: {
+		id:         "bucket-already-exists",
+		title:      "Bucket already exists",
 		statusCode: http.StatusConflict,
 		template:   "Bucket %v already exists",
 	},
 	BucketNotFound: {
+		id:         "bucket-not-found",
+		title:      "Bucket not found",
 		statusCode: http.StatusNotFound,
 		template:   "Bucket %v not found",
 	},
 	KeyNotFound: {
+		id:         "key-not-found",
+		title:      "Key not found",
 		statusCode: http.StatusNotFound,
 		template:   "Key %v not found on bucket %v",
 	},
 	InvalidKey: {
+		id:         "invalid-key",
+		title:      "Invalid key",
 		statusCode: http.StatusBadRequest,
 		template:   "Invalid key %v",
 	},
+	PreconditionFailed: {
+		id:         "precondition-failed",
+		title:      "Precondition failed",
+		statusCode: http.StatusPreconditionFailed,
+		template:   "Precondition failed for key %v on bucket %v",
+	},
+	ConditionFailed: {
+		id:         "condition-failed",
+		title:      "Condition failed",
+		statusCode: http.StatusConflict,
+		template:   "Condition failed for key %v on bucket %v",
+	},
+	UniqueViolation: {
+		id:         "unique-violation",
+		title:      "Unique violation",
+		statusCode: http.StatusConflict,
+		template:   "Value of key %v violates unique index %v on bucket %v",
+	},
+	AttachmentNotFound: {
+		id:         "attachment-not-found",
+		title:      "Attachment not found",
+		statusCode: http.StatusNotFound,
+		template:   "Attachment %v not found on key %v of bucket %v",
+	},
+	InvalidAttachmentName: {
+		id:         "invalid-attachment-name",
+		title:      "Invalid attachment name",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid attachment name %v",
+	},
+	AttachmentTooLarge: {
+		id:         "attachment-too-large",
+		title:      "Attachment too large",
+		statusCode: http.StatusRequestEntityTooLarge,
+		template:   "Attachment is larger than %v bytes",
+	},
 	MissingField: {
+		id:         "missing-field",
+		title:      "Missing field",
 		statusCode: http.StatusUnprocessableEntity,
 		template:   "Missing field: %v",
 	},
 	InvalidField: {
+		id:         "invalid-field",
+		title:      "Invalid field",
 		statusCode: http.StatusUnprocessableEntity,
 		template:   "Invalid value on field %v",
 	},
 	UnknownField: {
+		id:         "unknown-field",
+		title:      "Unknown field",
 		statusCode: http.StatusUnprocessableEntity,
 		template:   "Unknown field: %v",
 	},
+	ConstraintViolation: {
+		id:         "constraint-violation",
+		title:      "Constraint violation",
+		statusCode: http.StatusUnprocessableEntity,
+		template:   "Value of field %v violates constraint %v",
+	},
 	InvalidBucketName: {
+		id:         "invalid-bucket-name",
+		title:      "Invalid bucket name",
 		statusCode: http.StatusBadRequest,
 		template:   "Invalid bucket name %v",
 	},
 	SchemaMissing: {
+		id:         "schema-missing",
+		title:      "Schema missing",
 		statusCode: http.StatusBadRequest,
 		template:   "Schema must contain at least one field",
 	},
 	InvalidFieldName: {
+		id:         "invalid-field-name",
+		title:      "Invalid field name",
 		statusCode: http.StatusBadRequest,
 		template:   "Invalid field name %v",
 	},
 	InvalidFieldType: {
+		id:         "invalid-field-type",
+		title:      "Invalid field type",
 		statusCode: http.StatusBadRequest,
 		template:   "Invalid field type %v",
 	},
+	InvalidEnumValues: {
+		id:         "invalid-enum-values",
+		title:      "Invalid enum values",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid values for field %v, only enum fields have values, without duplicates",
+	},
+	InvalidFieldDefinition: {
+		id:         "invalid-field-definition",
+		title:      "Invalid field definition",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid definition of field %v, arrays need items, objects need fields and nested fields can't be indexed or searchable",
+	},
+	InvalidConstraint: {
+		id:         "invalid-constraint",
+		title:      "Invalid constraint",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid constraint %v on field %v",
+	},
+	InvalidJSONSchema: {
+		id:         "invalid-json-schema",
+		title:      "Invalid JSON Schema",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid JSON Schema at %v: %v",
+	},
+	UnsupportedSchemaKeyword: {
+		id:         "unsupported-schema-keyword",
+		title:      "Unsupported JSON Schema keyword",
+		statusCode: http.StatusBadRequest,
+		template:   "Unsupported JSON Schema keyword %v at %v",
+	},
+	EmptySchemaChange: {
+		id:         "empty-schema-change",
+		title:      "Empty schema change",
+		statusCode: http.StatusBadRequest,
+		template:   "Schema change must contain at least one operation",
+	},
+	FieldAlreadyExists: {
+		id:         "field-already-exists",
+		title:      "Field already exists",
+		statusCode: http.StatusConflict,
+		template:   "Field %v already exists",
+	},
+	IncompatibleSchemaChange: {
+		id:         "incompatible-schema-change",
+		title:      "Incompatible schema change",
+		statusCode: http.StatusConflict,
+		template:   "Schema change on field %v is incompatible with existing values",
+	},
 	BadRequestPaylod: {
+		id:         "bad-request-payload",
+		title:      "Bad request payload",
 		statusCode: http.StatusBadRequest,
 		template:   "Bad request: Invalid body",
 	},
+	InvalidPrecondition: {
+		id:         "invalid-precondition",
+		title:      "Invalid precondition",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid precondition %v",
+	},
+	InvalidOperation: {
+		id:         "invalid-operation",
+		title:      "Invalid operation",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid operation %v",
+	},
+	InvalidCondition: {
+		id:         "invalid-condition",
+		title:      "Invalid condition",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid condition for key %v",
+	},
+	InvalidTTL: {
+		id:         "invalid-ttl",
+		title:      "Invalid TTL",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid TTL %v",
+	},
+	InvalidTimestamp: {
+		id:         "invalid-timestamp",
+		title:      "Invalid timestamp",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid timestamp %v",
+	},
+	InvalidEventID: {
+		id:         "invalid-event-id",
+		title:      "Invalid event ID",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid event id %v",
+	},
+	InvalidCriteria: {
+		id:         "invalid-criteria",
+		title:      "Invalid criteria",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid criteria %v",
+	},
+	InvalidSort: {
+		id:         "invalid-sort",
+		title:      "Invalid sort",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid sort %v",
+	},
+	InvalidLimit: {
+		id:         "invalid-limit",
+		title:      "Invalid limit",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid limit %v, must be between 1 and %v",
+	},
+	InvalidCursor: {
+		id:         "invalid-cursor",
+		title:      "Invalid cursor",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid cursor %v",
+	},
+	InvalidAggregate: {
+		id:         "invalid-aggregate",
+		title:      "Invalid aggregate",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid aggregate %v",
+	},
+	InvalidFilter: {
+		id:         "invalid-filter",
+		title:      "Invalid filter",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid filter %v",
+	},
+	InvalidResultType: {
+		id:         "invalid-result-type",
+		title:      "Invalid result type",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid result type %v",
+	},
+	UnsupportedMediaType: {
+		id:         "unsupported-media-type",
+		title:      "Unsupported media type",
+		statusCode: http.StatusUnsupportedMediaType,
+		template:   "Unsupported media type %v",
+	},
+	HistoryNotEnabled: {
+		id:         "history-not-enabled",
+		title:      "History not enabled",
+		statusCode: http.StatusBadRequest,
+		template:   "History is not enabled on bucket %v",
+	},
+	InvalidBucketMode: {
+		id:         "invalid-bucket-mode",
+		title:      "Invalid bucket mode",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid bucket mode %v, it must be schema or document",
+	},
+	InvalidIndex: {
+		id:         "invalid-index",
+		title:      "Invalid index",
+		statusCode: http.StatusBadRequest,
+		template:   "Invalid index %v",
+	},
+	FieldIndexed: {
+		id:         "field-indexed",
+		title:      "Field indexed",
+		statusCode: http.StatusConflict,
+		template:   "Field %v is used by index %v",
+	},
+	FieldNotUnique: {
+		id:         "field-not-unique",
+		title:      "Field not unique",
+		statusCode: http.StatusBadRequest,
+		template:   "Field %v is not unique on bucket %v",
+	},
+	FieldNotSearchable: {
+		id:         "field-not-searchable",
+		title:      "Field not searchable",
+		statusCode: http.StatusBadRequest,
+		template:   "Field %v can't be searchable, only string fields can",
+	},
+	SearchNotEnabled: {
+		id:         "search-not-enabled",
+		title:      "Search not enabled",
+		statusCode: http.StatusBadRequest,
+		template:   "Full-text search is not enabled on bucket %v",
+	},
+	SearchNotSupported: {
+		id:         "search-not-supported",
+		title:      "Search not supported",
+		statusCode: http.StatusNotImplemented,
+		template:   "Full-text search is not supported by the server",
+	},
 	UnexpectedError: {
+		id:         "unexpected-error",
+		title:      "Unexpected error",
 		statusCode: http.StatusInternalServerError,
 		template:   "Unexpected error",
 	},
@@ -94,6 +382,15 @@ func (t ErrorType) ErrorCode() int {
 	return int(t)
 }
 
+// ID is a stable identifier of the error type, unlike the error code it doesn't depend on the declaration order
+func (t ErrorType) ID() string {
+	return errorTypes[t].id
+}
+
+func (t ErrorType) Title() string {
+	return errorTypes[t].title
+}
+
 func (t ErrorType) StatusCode() int {
 	return errorTypes[t].statusCode
 }
@@ -0,0 +1,28 @@
+package apperror
+
+import "testing"
+
+// the legacy error format exposes the error codes, so the codes of the first release can't change
+func TestLegacyErrorCodes(t *testing.T) {
+	codes := map[ErrorType]int{
+		BucketAlreadyExits: 1,
+		BucketNotFound:     2,
+		KeyNotFound:        3,
+		InvalidKey:         4,
+		MissingField:       5,
+		InvalidField:       6,
+		UnknownField:       7,
+		BadRequestPaylod:   8,
+		InvalidBucketName:  9,
+		SchemaMissing:      10,
+		InvalidFieldName:   11,
+		InvalidFieldType:   12,
+		UnexpectedError:    13,
+	}
+
+	for errorType, code := range codes {
+		if errorType.ErrorCode() != code {
+			t.Errorf("error code of %v is %v, expected %v", errorType.ID(), errorType.ErrorCode(), code)
+		}
+	}
+}
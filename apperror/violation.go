@@ -0,0 +1,59 @@
+package apperror
+
+// Violation is a field level error, reported together with the other violations of a request
+type Violation struct {
+	Field     string
+	ErrorType ErrorType
+	Message   string
+}
+
+// Violations collects the errors of several fields, to report all of them at once
+type Violations struct {
+	first      *Error
+	violations []Violation
+}
+
+// Add records the error of a field, nil errors are ignored
+func (v *Violations) Add(field string, err error) {
+	if err == nil {
+		return
+	}
+
+	appErr, ok := err.(*Error)
+	if !ok {
+		appErr = UnexpectedError.WithCause(err).(*Error)
+	}
+
+	if v.first == nil {
+		v.first = appErr
+	}
+
+	if len(appErr.Violations) > 0 {
+		v.violations = append(v.violations, appErr.Violations...)
+		return
+	}
+
+	violation := Violation{
+		Field:     field,
+		ErrorType: appErr.ErrorType,
+		Message:   appErr.Description,
+	}
+
+	v.violations = append(v.violations, violation)
+}
+
+func (v *Violations) Len() int {
+	return len(v.violations)
+}
+
+// Err returns nil without violations, otherwise the first error with the list of all violations
+func (v *Violations) Err() error {
+	if v.first == nil {
+		return nil
+	}
+
+	err := *v.first
+	err.Violations = v.violations
+
+	return &err
+}
@@ -0,0 +1,69 @@
+package bucket
+
+import (
+	"context"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func (s *BucketService) SetAttachment(ctx context.Context, name string, key string, attachment model.Attachment) error {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return apperror.BucketNotFound.New(name)
+	}
+
+	if len(attachment.ContentType) == 0 {
+		attachment.ContentType = model.DefaultAttachmentType
+	}
+
+	return bucket.StoreAttachment(ctx, key, attachment)
+}
+
+func (s *BucketService) Attachment(ctx context.Context, name string, key string, attachmentName string) (*model.Attachment, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, apperror.BucketNotFound.New(name)
+	}
+
+	attachment, err := bucket.ReadAttachment(ctx, key, attachmentName)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if attachment == nil {
+		return nil, apperror.AttachmentNotFound.New(attachmentName, key, name)
+	}
+
+	return attachment, nil
+}
+
+func (s *BucketService) DeleteAttachment(ctx context.Context, name string, key string, attachmentName string) error {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return apperror.BucketNotFound.New(name)
+	}
+
+	deleted, err := bucket.DeleteAttachment(ctx, key, attachmentName)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return apperror.AttachmentNotFound.New(attachmentName, key, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package bucket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+// defaults are only applied when the key is created, replacing or patching a key keeps missing fields missing
+func TestDefaultValues(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{
+		{Name: "name", Type: model.StringDataType},
+		{Name: "status", Type: model.StringDataType, Default: "active"},
+	})
+
+	steps := []struct {
+		description string
+		update      func() error
+		status      any
+	}{
+		{
+			description: "creating the key",
+			update: func() error {
+				_, err := s.SetValue(ctx, "people", "k1", model.Object{"name": "Joe"}, model.Precondition{}, 0)
+				return err
+			},
+			status: "active",
+		},
+		{
+			description: "patching the status",
+			update: func() error {
+				_, err := s.PatchValue(ctx, "people", "k1", model.Object{"status": nil}, model.Precondition{}, 0)
+				return err
+			},
+		},
+		{
+			description: "replacing the key",
+			update: func() error {
+				_, err := s.SetValue(ctx, "people", "k1", model.Object{"name": "Ann"}, model.Precondition{}, 0)
+				return err
+			},
+		},
+		{
+			description: "deleting and creating the key on a transaction",
+			update: func() error {
+				_, err := s.Transaction(ctx, []model.Operation{
+					{Type: model.DeleteOperation, Bucket: "people", Key: "k1"},
+					{Type: model.SetOperation, Bucket: "people", Key: "k1", Value: model.Object{"name": "Ann"}},
+				})
+				return err
+			},
+			status: "active",
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.update(); err != nil {
+			t.Fatalf("%v: %v", step.description, err)
+		}
+
+		value, _, err := s.Value(ctx, "people", "k1")
+		if err != nil {
+			t.Fatalf("%v: reading value: %v", step.description, err)
+		}
+
+		if value["status"] != step.status {
+			t.Errorf("%v: status is %v, expected %v", step.description, value["status"], step.status)
+		}
+	}
+}
+
+func TestConstraintViolations(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	minScore, maxScore := 0.0, 100.0
+	minLength, maxLength := 2, 4
+
+	createTestBucket(t, s, "players", []model.Field{
+		{Name: "name", Type: model.StringDataType, Pattern: "^[A-Z][a-z]+$"},
+		{Name: "score", Type: model.NumberDataType, Min: &minScore, Max: &maxScore},
+		{Name: "code", Type: model.StringDataType, MinLength: &minLength, MaxLength: &maxLength},
+	})
+
+	valid := model.Object{"name": "Joe", "score": json.Number("100"), "code": "ab"}
+	if _, err := s.SetValue(ctx, "players", "k1", valid, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing a valid value: %v", err)
+	}
+
+	// all the violations are reported together
+	invalid := model.Object{"name": "joe", "score": json.Number("100.5"), "code": "abcde"}
+
+	_, err := s.SetValue(ctx, "players", "k2", invalid, model.Precondition{}, 0)
+
+	appErr := appError(err)
+	if appErr == nil || appErr.ErrorType != apperror.ConstraintViolation {
+		t.Fatalf("expected a constraint violation, got %v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, violation := range appErr.Violations {
+		fields[violation.Field] = true
+	}
+
+	for _, field := range []string{"name", "score", "code"} {
+		if !fields[field] {
+			t.Errorf("violation of %v not reported on %v", field, appErr.Violations)
+		}
+	}
+
+	// patches are validated on the resulting value
+	_, err = s.PatchValue(ctx, "players", "k1", model.Object{"code": "a"}, model.Precondition{}, 0)
+	if appError(err) == nil || appError(err).ErrorType != apperror.ConstraintViolation {
+		t.Errorf("expected a constraint violation patching the value, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package bucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestCursorPagination(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{{Name: "age", Type: model.IntegerDataType}})
+
+	// ages repeat and are missing, so the key decides the order of the ties
+	ages := map[string]any{
+		"k1": json.Number("30"),
+		"k2": json.Number("20"),
+		"k3": json.Number("30"),
+		"k4": nil,
+		"k5": json.Number("40"),
+		"k6": json.Number("20"),
+		"k7": nil,
+	}
+
+	for key, age := range ages {
+		value := model.Object{}
+		if age != nil {
+			value["age"] = age
+		}
+
+		if _, err := s.SetValue(ctx, "people", key, value, model.Precondition{}, 0); err != nil {
+			t.Fatalf("storing %v: %v", key, err)
+		}
+	}
+
+	cases := map[string][]string{
+		"":     {"k1", "k2", "k3", "k4", "k5", "k6", "k7"},
+		"age":  {"k4", "k7", "k2", "k6", "k1", "k3", "k5"},
+		"-age": {"k5", "k1", "k3", "k2", "k6", "k4", "k7"},
+	}
+
+	for sort, expected := range cases {
+		parameters := url.Values{model.LimitParameter: {"2"}}
+		if len(sort) > 0 {
+			parameters.Set(model.SortParameter, sort)
+		}
+
+		keys := make([]string, 0)
+
+		for pages := 1; ; pages++ {
+			page, err := s.FindKeys(ctx, "people", parameters)
+			if err != nil {
+				t.Fatalf("sort %q, page %v: %v", sort, pages, err)
+			}
+
+			keys = append(keys, page.Items...)
+
+			if len(page.Next) == 0 {
+				if pages != 4 {
+					t.Errorf("sort %q: expected 4 pages, got %v", sort, pages)
+				}
+
+				break
+			}
+
+			parameters.Set(model.CursorParameter, page.Next)
+		}
+
+		if !slices.Equal(keys, expected) {
+			t.Errorf("sort %q: paged %v, expected %v", sort, keys, expected)
+		}
+	}
+}
+
+// a cursor is only valid for the sort it was created with
+func TestInvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{{Name: "age", Type: model.IntegerDataType}})
+
+	for _, key := range []string{"k1", "k2", "k3"} {
+		if _, err := s.SetValue(ctx, "people", key, model.Object{"age": json.Number("30")}, model.Precondition{}, 0); err != nil {
+			t.Fatalf("storing %v: %v", key, err)
+		}
+	}
+
+	page, err := s.FindKeys(ctx, "people", url.Values{model.LimitParameter: {"1"}, model.SortParameter: {"age"}})
+	if err != nil {
+		t.Fatalf("reading first page: %v", err)
+	}
+
+	queries := []url.Values{
+		{model.CursorParameter: {page.Next}, model.SortParameter: {"-age"}},
+		{model.CursorParameter: {page.Next}},
+		{model.CursorParameter: {"not a cursor"}},
+	}
+
+	for _, parameters := range queries {
+		_, err = s.FindKeys(ctx, "people", parameters)
+		if appError(err) == nil || appError(err).ErrorType != apperror.InvalidCursor {
+			t.Errorf("expected an invalid cursor for %v, got %v", parameters, err)
+		}
+	}
+}
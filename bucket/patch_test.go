@@ -0,0 +1,63 @@
+package bucket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestPatchValue(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{
+		{Name: "name", Type: model.StringDataType, Required: true},
+		{Name: "address", Type: model.ObjectDataType, Fields: []model.Field{
+			{Name: "city", Type: model.StringDataType},
+			{Name: "street", Type: model.StringDataType},
+		}},
+	})
+
+	_, err := s.PatchValue(ctx, "people", "k1", model.Object{"name": "Joe"}, model.Precondition{}, 0)
+	if appError(err) == nil || appError(err).ErrorType != apperror.KeyNotFound {
+		t.Errorf("expected a missing key patching k1, got %v", err)
+	}
+
+	value := model.Object{"name": "Joe", "address": map[string]any{"city": "Lisbon", "street": "Main"}}
+	if _, err = s.SetValue(ctx, "people", "k1", value, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	version, err := s.PatchValue(ctx, "people", "k1", model.Object{"address": map[string]any{"city": "Porto"}}, model.Precondition{}, 0)
+	if err != nil || version != 2 {
+		t.Fatalf("patching nested field: version %v, error %v", version, err)
+	}
+
+	value, _, err = s.Value(ctx, "people", "k1")
+	if err != nil {
+		t.Fatalf("reading value: %v", err)
+	}
+
+	address, _ := value["address"].(map[string]any)
+	if value["name"] != "Joe" || address["city"] != "Porto" || address["street"] != "Main" {
+		t.Errorf("unexpected value after patching %v", value)
+	}
+
+	// a patch without changes doesn't create a new version
+	version, err = s.PatchValue(ctx, "people", "k1", model.Object{"name": "Joe"}, model.Precondition{}, 0)
+	if err != nil || version != 2 {
+		t.Errorf("patching without changes: version %v, error %v", version, err)
+	}
+
+	_, err = s.PatchValue(ctx, "people", "k1", model.Object{"name": nil}, model.Precondition{}, 0)
+	if appError(err) == nil {
+		t.Errorf("expected removing a not-null field to be rejected, got %v", err)
+	}
+
+	_, err = s.PatchValue(ctx, "people", "k1", model.Object{"name": "Ann"}, model.Precondition{Versions: []int64{1}}, 0)
+	if appError(err) == nil || appError(err).ErrorType != apperror.PreconditionFailed {
+		t.Errorf("expected a failed precondition patching a stale version, got %v", err)
+	}
+}
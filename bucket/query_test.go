@@ -0,0 +1,96 @@
+package bucket
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestQueryOperators(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{
+		{Name: "name", Type: model.StringDataType},
+		{Name: "age", Type: model.IntegerDataType},
+		{Name: "email", Type: model.StringDataType},
+	})
+
+	values := map[string]model.Object{
+		"k1": {"name": "Joe", "age": json.Number("30"), "email": "joe@example.com"},
+		"k2": {"name": "John", "age": json.Number("40")},
+		"k3": {"name": "Ann", "age": json.Number("25"), "email": "ann@example.com"},
+		"k4": {"name": "J_e", "email": "je@example.com"},
+	}
+
+	for key, value := range values {
+		if _, err := s.SetValue(ctx, "people", key, value, model.Precondition{}, 0); err != nil {
+			t.Fatalf("storing %v: %v", key, err)
+		}
+	}
+
+	// missing values are different from any value, and prefixes are compared literally
+	cases := map[string][]string{
+		"age[gt]=30":              {"k2"},
+		"age[gte]=30":             {"k1", "k2"},
+		"age[lt]=30":              {"k3"},
+		"age[gte]=25&age[lte]=30": {"k1", "k3"},
+		"age[ne]=30":              {"k2", "k3", "k4"},
+		"age=30":                  {"k1"},
+		"name[prefix]=Jo":         {"k1", "k2"},
+		"name[prefix]=J_":         {"k4"},
+		"name[prefix]=%25":        {},
+		"email[null]=true":        {"k2"},
+		"email[null]=false":       {"k1", "k3", "k4"},
+		"age[null]=true":          {"k4"},
+	}
+
+	for query, expected := range cases {
+		parameters, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("parsing %v: %v", query, err)
+		}
+
+		page, err := s.FindKeys(ctx, "people", parameters)
+		if err != nil {
+			t.Errorf("%v: %v", query, err)
+			continue
+		}
+
+		if !slices.Equal(page.Items, expected) {
+			t.Errorf("%v found %v, expected %v", query, page.Items, expected)
+		}
+	}
+}
+
+func TestInvalidQueryOperators(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{
+		{Name: "name", Type: model.StringDataType},
+		{Name: "age", Type: model.IntegerDataType},
+	})
+
+	queries := []string{
+		"age[prefix]=3",
+		"age[gt]=old",
+		"name[null]=maybe",
+		"name[like]=Jo",
+	}
+
+	for _, query := range queries {
+		parameters, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("parsing %v: %v", query, err)
+		}
+
+		if _, err = s.FindKeys(ctx, "people", parameters); appError(err) == nil {
+			t.Errorf("expected %v to be rejected, got %v", query, err)
+		}
+	}
+}
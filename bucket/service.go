@@ -3,6 +3,8 @@ package bucket
 import (
 	"context"
 	"net/url"
+	"slices"
+	"time"
 
 	"github.com/jjmrocha/oblivion/apperror"
 	"github.com/jjmrocha/oblivion/model"
@@ -30,7 +32,31 @@ func (s *BucketService) BucketList(ctx context.Context) ([]string, error) {
 	return bucketList, nil
 }
 
-func (s *BucketService) CreateBucket(ctx context.Context, name string, schema []model.Field) (repo.Bucket, error) {
+// Buckets returns the definition of all buckets
+func (s *BucketService) Buckets(ctx context.Context) ([]repo.Bucket, error) {
+	names, err := s.repo.BucketNames(ctx)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	buckets := make([]repo.Bucket, 0, len(names))
+
+	for _, name := range names {
+		bucket, err := s.repo.GetBucket(ctx, name)
+		if err != nil {
+			return nil, apperror.UnexpectedError.WithCause(err)
+		}
+
+		// dropped after listing the names
+		if bucket != nil {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	return buckets, nil
+}
+
+func (s *BucketService) CreateBucket(ctx context.Context, name string, schema []model.Field, options model.BucketOptions) (repo.Bucket, error) {
 	bucket, err := s.repo.GetBucket(ctx, name)
 	if err != nil {
 		return nil, apperror.UnexpectedError.WithCause(err)
@@ -40,7 +66,7 @@ func (s *BucketService) CreateBucket(ctx context.Context, name string, schema []
 		return nil, apperror.BucketAlreadyExits.New(name)
 	}
 
-	return s.repo.NewBucket(ctx, name, schema)
+	return s.repo.NewBucket(ctx, name, schema, options)
 }
 
 func (s *BucketService) GetBucket(ctx context.Context, name string) (repo.Bucket, error) {
@@ -71,8 +97,9 @@ func (s *BucketService) DeleteBucket(ctx context.Context, name string) error {
 	return s.repo.DropBucket(ctx, name)
 }
 
-func (s *BucketService) Value(ctx context.Context, name string, key string) (model.Object, error) {
+func (s *BucketService) UpdateSchema(ctx context.Context, name string, change model.SchemaChange) (repo.Bucket, error) {
 	bucket, err := s.repo.GetBucket(ctx, name)
+
 	if err != nil {
 		return nil, apperror.UnexpectedError.WithCause(err)
 	}
@@ -81,38 +108,247 @@ func (s *BucketService) Value(ctx context.Context, name string, key string) (mod
 		return nil, apperror.BucketNotFound.New(name)
 	}
 
-	object, err := bucket.Read(ctx, key)
+	if err := valid.SchemaChange(change, bucket.Schema(), bucket.Options()); err != nil {
+		return nil, err
+	}
+
+	return s.repo.AlterBucket(ctx, name, change)
+}
+
+func (s *BucketService) Value(ctx context.Context, name string, key string) (model.Object, int64, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, 0, apperror.BucketNotFound.New(name)
+	}
+
+	object, version, err := bucket.Read(ctx, key)
 	if err != nil {
-		return nil, apperror.UnexpectedError.WithCause(err)
+		return nil, 0, apperror.UnexpectedError.WithCause(err)
 	}
 
 	if object == nil {
+		return nil, 0, apperror.KeyNotFound.New(key, name)
+	}
+
+	return object, version, nil
+}
+
+func (s *BucketService) ValueAt(ctx context.Context, name string, key string, at time.Time) (model.Object, int64, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, 0, apperror.BucketNotFound.New(name)
+	}
+
+	if !bucket.Options().History {
+		return nil, 0, apperror.HistoryNotEnabled.New(name)
+	}
+
+	object, version, err := bucket.ReadAt(ctx, key, at)
+	if err != nil {
+		return nil, 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if object == nil {
+		return nil, 0, apperror.KeyNotFound.New(key, name)
+	}
+
+	return object, version, nil
+}
+
+func (s *BucketService) History(ctx context.Context, name string, key string) ([]model.Revision, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, apperror.BucketNotFound.New(name)
+	}
+
+	if !bucket.Options().History {
+		return nil, apperror.HistoryNotEnabled.New(name)
+	}
+
+	revisions, err := bucket.History(ctx, key)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if len(revisions) == 0 {
 		return nil, apperror.KeyNotFound.New(key, name)
 	}
 
-	return object, nil
+	return revisions, nil
 }
 
-func (s *BucketService) SetValue(ctx context.Context, name string, key string, value model.Object) error {
+func (s *BucketService) ValueBy(ctx context.Context, name string, fieldName string, value string) (model.Entry, error) {
 	bucket, err := s.repo.GetBucket(ctx, name)
+	if err != nil {
+		return model.Entry{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return model.Entry{}, apperror.BucketNotFound.New(name)
+	}
+
+	position := slices.IndexFunc(bucket.Schema(), func(field model.Field) bool {
+		return field.Name == fieldName
+	})
+	if position < 0 {
+		return model.Entry{}, apperror.UnknownField.New(fieldName)
+	}
 
+	if !bucket.Options().IsUnique(fieldName) {
+		return model.Entry{}, apperror.FieldNotUnique.New(fieldName, name)
+	}
+
+	converted, err := bucket.Schema()[position].Type.Convert(value)
 	if err != nil {
-		return apperror.UnexpectedError.WithCause(err)
+		return model.Entry{}, apperror.InvalidField.WithCause(err, fieldName)
+	}
+
+	query := model.Query{
+		Criteria: model.Criteria{
+			{Field: fieldName, Operator: model.EqualOperator, Values: []any{converted}},
+		},
+		Limit: 1,
+	}
+
+	entries, err := bucket.Search(ctx, query)
+	if err != nil {
+		return model.Entry{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if len(entries.Items) == 0 {
+		return model.Entry{}, apperror.KeyNotFound.New(fieldName+"="+value, name)
+	}
+
+	return entries.Items[0], nil
+}
+
+func (s *BucketService) SetValue(ctx context.Context, name string, key string, value model.Object, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	version, err := setValue(ctx, tx, name, key, value, precondition, ttl)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return version, nil
+}
+
+// setValue checks if the key exists on the same transaction it is written, as defaults only apply to new keys
+func setValue(ctx context.Context, tx repo.Transaction, name string, key string, value model.Object, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	bucket, err := tx.Bucket(ctx, name)
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
 	}
 
 	if bucket == nil {
-		return apperror.BucketNotFound.New(name)
+		return 0, apperror.BucketNotFound.New(name)
+	}
+
+	value, err = newValue(ctx, bucket, key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return bucket.Store(ctx, key, value.Normalize(bucket.Schema()), precondition, ttl)
+}
+
+// newValue is the value to store on the key, with the default values when the key doesn't exist, after validating it
+func newValue(ctx context.Context, bucket repo.Bucket, key string, value model.Object) (model.Object, error) {
+	current, _, err := bucket.Read(ctx, key)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if current == nil {
+		value = value.WithDefaults(bucket.Schema())
+	}
+
+	return value, valid.Value(value, bucket.Schema(), bucket.Options())
+}
+
+// PatchValue applies a JSON merge patch to the value of an existing key, the result is validated as a whole
+// and only the changed fields are written
+func (s *BucketService) PatchValue(ctx context.Context, name string, key string, patch model.Object, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	version, err := patchValue(ctx, tx, name, key, patch, precondition, ttl)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return version, nil
+}
+
+// patchValue reads and writes the key on the same transaction, so changes made meanwhile aren't lost
+func patchValue(ctx context.Context, tx repo.Transaction, name string, key string, patch model.Object, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	bucket, err := tx.Bucket(ctx, name)
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return 0, apperror.BucketNotFound.New(name)
+	}
+
+	current, version, err := bucket.Read(ctx, key)
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if current == nil {
+		return 0, apperror.KeyNotFound.New(key, name)
+	}
+
+	if !precondition.Check(version) {
+		return 0, apperror.PreconditionFailed.New(key, name)
 	}
 
-	err = valid.Object(value, bucket.Schema())
+	value := current.MergePatch(patch)
+
+	err = valid.Value(value, bucket.Schema(), bucket.Options())
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return bucket.Store(ctx, key, value)
+	value = value.Normalize(bucket.Schema())
+
+	changed := current.ChangedFields(value)
+	if len(changed) == 0 && ttl == 0 {
+		return version, nil
+	}
+
+	return bucket.Update(ctx, key, value, changed, model.Precondition{Versions: []int64{version}}, ttl)
 }
 
-func (s *BucketService) DeleteValue(ctx context.Context, name string, key string) error {
+func (s *BucketService) DeleteValue(ctx context.Context, name string, key string, precondition model.Precondition) error {
 	bucket, err := s.repo.GetBucket(ctx, name)
 
 	if err != nil {
@@ -123,10 +359,113 @@ func (s *BucketService) DeleteValue(ctx context.Context, name string, key string
 		return apperror.BucketNotFound.New(name)
 	}
 
-	return bucket.Delete(ctx, key)
+	return bucket.Delete(ctx, key, precondition)
+}
+
+func (s *BucketService) Batch(ctx context.Context, name string, operations []model.Operation) ([]model.OperationResult, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, apperror.BucketNotFound.New(name)
+	}
+
+	results := model.NewOperationResults(operations)
+	prepared := make([]model.Operation, len(operations))
+	var firstErr error
+
+	for i, operation := range operations {
+		operation.Bucket = name
+		prepared[i] = operation
+
+		// values are validated again when applied, without defaults when the key exists
+		if err := valid.Operation(operation.WithDefaults(bucket.Schema()), bucket.Schema(), bucket.Options()); err != nil {
+			results[i].Status = model.OperationFailed
+			results[i].Error = apperror.Describe(err)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return nil, apperror.WithDetails(firstErr, results)
+	}
+
+	return s.applyOperations(ctx, prepared, results)
+}
+
+func (s *BucketService) Changes(ctx context.Context, name string, since int64, limit int) ([]model.Change, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, apperror.BucketNotFound.New(name)
+	}
+
+	changes, err := bucket.Changes(ctx, since, limit)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return changes, nil
+}
+
+func (s *BucketService) LastChange(ctx context.Context, name string) (int64, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return 0, apperror.BucketNotFound.New(name)
+	}
+
+	seq, err := bucket.LastChange(ctx)
+	if err != nil {
+		return 0, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return seq, nil
+}
+
+func (s *BucketService) FindKeys(ctx context.Context, name string, parameters url.Values) (model.Page[string], error) {
+	bucket, query, err := s.prepareQuery(ctx, name, parameters)
+	if err != nil {
+		return model.Page[string]{}, err
+	}
+
+	keys, err := bucket.Keys(ctx, query)
+	if err != nil {
+		return model.Page[string]{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return keys, nil
 }
 
-func (s *BucketService) FindKeys(ctx context.Context, name string, criteria url.Values) ([]string, error) {
+func (s *BucketService) FindObjects(ctx context.Context, name string, parameters url.Values) (model.Page[model.Entry], error) {
+	bucket, query, err := s.prepareQuery(ctx, name, parameters)
+	if err != nil {
+		return model.Page[model.Entry]{}, err
+	}
+
+	entries, err := bucket.Search(ctx, query)
+	if err != nil {
+		return model.Page[model.Entry]{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return entries, nil
+}
+
+func (s *BucketService) Aggregate(ctx context.Context, name string, parameters url.Values) ([]model.AggregateResult, error) {
 	bucket, err := s.repo.GetBucket(ctx, name)
 
 	if err != nil {
@@ -137,14 +476,103 @@ func (s *BucketService) FindKeys(ctx context.Context, name string, criteria url.
 		return nil, apperror.BucketNotFound.New(name)
 	}
 
-	if err := valid.Criteria(criteria, bucket.Schema()); err != nil {
+	schema := bucket.Options().WithDocument(bucket.Schema())
+
+	if err := valid.Aggregation(parameters, schema); err != nil {
 		return nil, err
 	}
 
-	normalized, err := model.Convert(criteria, bucket.Schema())
+	aggregation, err := model.NewAggregation(parameters, schema)
 	if err != nil {
 		return nil, err
 	}
 
-	return bucket.Keys(ctx, normalized)
+	results, err := bucket.Aggregate(ctx, aggregation)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return results, nil
+}
+
+func (s *BucketService) QueryKeys(ctx context.Context, name string, request model.SearchRequest) (model.Page[string], error) {
+	bucket, query, err := s.prepareSearch(ctx, name, request)
+	if err != nil {
+		return model.Page[string]{}, err
+	}
+
+	keys, err := bucket.Keys(ctx, query)
+	if err != nil {
+		return model.Page[string]{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return keys, nil
+}
+
+func (s *BucketService) QueryObjects(ctx context.Context, name string, request model.SearchRequest) (model.Page[model.Entry], error) {
+	bucket, query, err := s.prepareSearch(ctx, name, request)
+	if err != nil {
+		return model.Page[model.Entry]{}, err
+	}
+
+	entries, err := bucket.Search(ctx, query)
+	if err != nil {
+		return model.Page[model.Entry]{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	return entries, nil
+}
+
+func (s *BucketService) prepareSearch(ctx context.Context, name string, request model.SearchRequest) (repo.Bucket, model.Query, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+
+	if err != nil {
+		return nil, model.Query{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, model.Query{}, apperror.BucketNotFound.New(name)
+	}
+
+	schema := bucket.Options().WithDocument(bucket.Schema())
+
+	if err := valid.SearchRequest(request, schema); err != nil {
+		return nil, model.Query{}, err
+	}
+
+	query, err := request.Query(schema)
+	if err != nil {
+		return nil, model.Query{}, err
+	}
+
+	return bucket, query, nil
+}
+
+func (s *BucketService) prepareQuery(ctx context.Context, name string, parameters url.Values) (repo.Bucket, model.Query, error) {
+	bucket, err := s.repo.GetBucket(ctx, name)
+
+	if err != nil {
+		return nil, model.Query{}, apperror.UnexpectedError.WithCause(err)
+	}
+
+	if bucket == nil {
+		return nil, model.Query{}, apperror.BucketNotFound.New(name)
+	}
+
+	schema := bucket.Options().WithDocument(bucket.Schema())
+
+	if err := valid.Query(parameters, schema); err != nil {
+		return nil, model.Query{}, err
+	}
+
+	if parameters.Has(model.SearchParameter) && len(model.SearchableFields(bucket.Schema())) == 0 {
+		return nil, model.Query{}, apperror.SearchNotEnabled.New(name)
+	}
+
+	query, err := model.NewQuery(parameters, schema)
+	if err != nil {
+		return nil, model.Query{}, err
+	}
+
+	return bucket, query, nil
 }
@@ -0,0 +1,38 @@
+package bucket
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+	"github.com/jjmrocha/oblivion/repo/relational"
+)
+
+func newTestService(t *testing.T) *BucketService {
+	t.Helper()
+
+	repo := relational.New("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(repo.Close)
+
+	return NewService(repo)
+}
+
+func createTestBucket(t *testing.T, s *BucketService, name string, schema []model.Field) {
+	t.Helper()
+
+	if _, err := s.CreateBucket(context.Background(), name, schema, model.BucketOptions{}); err != nil {
+		t.Fatalf("creating bucket %v: %v", name, err)
+	}
+}
+
+func appError(err error) *apperror.Error {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package bucket
+
+import (
+	"context"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+	"github.com/jjmrocha/oblivion/repo"
+	"github.com/jjmrocha/oblivion/valid"
+)
+
+func (s *BucketService) Transaction(ctx context.Context, operations []model.Operation) ([]model.OperationResult, error) {
+	results := model.NewOperationResults(operations)
+	definitions := make(map[string]repo.Bucket)
+	var firstErr error
+
+	for i, operation := range operations {
+		if err := s.validTransactionOperation(ctx, operation, definitions); err != nil {
+			results[i].Status = model.OperationFailed
+			results[i].Error = apperror.Describe(err)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return nil, apperror.WithDetails(firstErr, results)
+	}
+
+	return s.applyOperations(ctx, operations, results)
+}
+
+// applyOperations applies the operations of a batch or transaction on the same transaction,
+// the first failure rolls back all the operations
+func (s *BucketService) applyOperations(ctx context.Context, operations []model.Operation, results []model.OperationResult) ([]model.OperationResult, error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		return nil, apperror.UnexpectedError.WithCause(err)
+	}
+
+	buckets := make(map[string]repo.Bucket)
+
+	for i, operation := range operations {
+		version, err := applyOperation(ctx, tx, buckets, operation)
+		if err != nil {
+			tx.Rollback()
+			results[i].Status = model.OperationFailed
+			results[i].Error = apperror.Describe(err)
+			return nil, apperror.WithDetails(err, model.AbortResults(results))
+		}
+
+		results[i].Status = model.OperationApplied
+		results[i].Version = version
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, apperror.WithDetails(err, model.AbortResults(results))
+	}
+
+	return results, nil
+}
+
+// validTransactionOperation validates the operation with the default values of its bucket,
+// values are validated again when applied, without defaults when the key exists
+func (s *BucketService) validTransactionOperation(ctx context.Context, operation model.Operation, definitions map[string]repo.Bucket) error {
+	if err := valid.BucketName(operation.Bucket); err != nil {
+		return err
+	}
+
+	bucket, found := definitions[operation.Bucket]
+	if !found {
+		var err error
+
+		bucket, err = s.repo.GetBucket(ctx, operation.Bucket)
+		if err != nil {
+			return apperror.UnexpectedError.WithCause(err)
+		}
+
+		if bucket == nil {
+			return apperror.BucketNotFound.New(operation.Bucket)
+		}
+
+		definitions[operation.Bucket] = bucket
+	}
+
+	return valid.Operation(operation.WithDefaults(bucket.Schema()), bucket.Schema(), bucket.Options())
+}
+
+func applyOperation(ctx context.Context, tx repo.Transaction, buckets map[string]repo.Bucket, operation model.Operation) (int64, error) {
+	bucket, found := buckets[operation.Bucket]
+	if !found {
+		var err error
+
+		bucket, err = tx.Bucket(ctx, operation.Bucket)
+		if err != nil {
+			return 0, err
+		}
+
+		if bucket == nil {
+			return 0, apperror.BucketNotFound.New(operation.Bucket)
+		}
+
+		buckets[operation.Bucket] = bucket
+	}
+
+	if operation.Type == model.SetOperation {
+		value, err := newValue(ctx, bucket, operation.Key, operation.Value)
+		if err != nil {
+			return 0, err
+		}
+
+		operation.Value = value
+	}
+
+	return bucket.Apply(ctx, operation.Normalize(bucket.Schema()))
+}
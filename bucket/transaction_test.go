@@ -0,0 +1,131 @@
+package bucket
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType}}
+	createTestBucket(t, s, "people", schema)
+	createTestBucket(t, s, "orders", schema)
+
+	exists := true
+	operations := []model.Operation{
+		{Type: model.SetOperation, Bucket: "people", Key: "k1", Value: model.Object{"name": "Joe"}},
+		{Type: model.SetOperation, Bucket: "orders", Key: "o1", Value: model.Object{"name": "book"}},
+		{Type: model.CheckOperation, Bucket: "people", Key: "k2", Condition: &model.Condition{Exists: &exists}},
+	}
+
+	_, err := s.Transaction(ctx, operations)
+	if err == nil {
+		t.Fatal("expected the transaction to fail")
+	}
+
+	statuses := operationStatuses(t, err)
+	expected := []model.OperationStatus{model.OperationAborted, model.OperationAborted, model.OperationFailed}
+	if !slices.Equal(statuses, expected) {
+		t.Errorf("unexpected statuses %v, expected %v", statuses, expected)
+	}
+
+	for _, operation := range operations[:2] {
+		if _, _, err = s.Value(ctx, operation.Bucket, operation.Key); appError(err) == nil || appError(err).ErrorType != apperror.KeyNotFound {
+			t.Errorf("key %v of %v wasn't rolled back: %v", operation.Key, operation.Bucket, err)
+		}
+	}
+}
+
+func TestBatchRollback(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{{Name: "name", Type: model.StringDataType}})
+
+	if _, err := s.SetValue(ctx, "people", "k1", model.Object{"name": "Joe"}, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	exists := false
+	operations := []model.Operation{
+		{Type: model.DeleteOperation, Key: "k1"},
+		{Type: model.SetOperation, Key: "k2", Value: model.Object{"name": "Ann"}},
+		{Type: model.CheckOperation, Key: "k2", Condition: &model.Condition{Exists: &exists}},
+	}
+
+	_, err := s.Batch(ctx, "people", operations)
+	if err == nil {
+		t.Fatal("expected the batch to fail")
+	}
+
+	statuses := operationStatuses(t, err)
+	expected := []model.OperationStatus{model.OperationAborted, model.OperationAborted, model.OperationFailed}
+	if !slices.Equal(statuses, expected) {
+		t.Errorf("unexpected statuses %v, expected %v", statuses, expected)
+	}
+
+	if value, _, err := s.Value(ctx, "people", "k1"); err != nil || value["name"] != "Joe" {
+		t.Errorf("deleted key wasn't restored, value %v, error %v", value, err)
+	}
+
+	if _, _, err := s.Value(ctx, "people", "k2"); appError(err) == nil || appError(err).ErrorType != apperror.KeyNotFound {
+		t.Errorf("stored key wasn't rolled back: %v", err)
+	}
+
+	// the operations of the request are kept unchanged
+	if operations[0].Bucket != "" {
+		t.Errorf("request operation changed to %v", operations[0])
+	}
+}
+
+// invalid operations are rejected before any operation is applied
+func TestInvalidTransaction(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	createTestBucket(t, s, "people", []model.Field{{Name: "name", Type: model.StringDataType}})
+
+	operations := []model.Operation{
+		{Type: model.SetOperation, Bucket: "people", Key: "k1", Value: model.Object{"name": "Joe"}},
+		{Type: model.SetOperation, Bucket: "people", Key: "k2", Value: model.Object{"name": 10}},
+	}
+
+	_, err := s.Transaction(ctx, operations)
+
+	statuses := operationStatuses(t, err)
+	expected := []model.OperationStatus{model.OperationAborted, model.OperationFailed}
+	if !slices.Equal(statuses, expected) {
+		t.Errorf("unexpected statuses %v, expected %v", statuses, expected)
+	}
+
+	if _, _, err = s.Value(ctx, "people", "k1"); appError(err) == nil || appError(err).ErrorType != apperror.KeyNotFound {
+		t.Errorf("key of an invalid transaction was stored: %v", err)
+	}
+}
+
+func operationStatuses(t *testing.T, err error) []model.OperationStatus {
+	t.Helper()
+
+	appErr := appError(err)
+	if appErr == nil {
+		t.Fatalf("expected the results on the error, got %v", err)
+	}
+
+	results, ok := appErr.Details.([]model.OperationResult)
+	if !ok {
+		t.Fatalf("unexpected error details %v", appErr.Details)
+	}
+
+	statuses := make([]model.OperationStatus, 0, len(results))
+	for _, result := range results {
+		statuses = append(statuses, result.Status)
+	}
+
+	return statuses
+}
@@ -65,3 +65,17 @@ func (c *Context) NoContent() (*Response, error) {
 
 	return &resp, nil
 }
+
+func (c *Context) Stream(contentType string, stream StreamFunc) (*Response, error) {
+	resp := Response{
+		Status:      http.StatusOK,
+		ContentType: contentType,
+		Stream:      stream,
+	}
+
+	return &resp, nil
+}
+
+func (c *Context) SetHeader(name string, value string) {
+	c.Writer.Header().Set(name, value)
+}
@@ -3,50 +3,174 @@ package httprouter
 import (
 	"encoding/json"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/jjmrocha/oblivion/apperror"
 )
 
+const (
+	jsonContentType    = "application/json"
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:oblivion:error:"
+)
+
+// errorPayload is the legacy error format, used by clients accepting application/json but not application/problem+json
 type errorPayload struct {
-	Status      int    `json:"status"`
-	ErrorCode   int    `json:"error-code"`
-	Description string `json:"description"`
+	Status      int                `json:"status"`
+	ErrorCode   int                `json:"error-code"`
+	Description string             `json:"description"`
+	Details     any                `json:"details,omitempty"`
+	Errors      []violationPayload `json:"errors,omitempty"`
+}
+
+type violationPayload struct {
+	Field   string `json:"field"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
 }
 
-func errorResponse(err error) *Response {
-	errorType := apperror.UnexpectedError
-	description := err.Error()
+// problemPayload follows RFC 7807, with the error id, details and violations as extension members
+type problemPayload struct {
+	Type     string                    `json:"type"`
+	Title    string                    `json:"title"`
+	Status   int                       `json:"status"`
+	Detail   string                    `json:"detail"`
+	Instance string                    `json:"instance"`
+	Code     string                    `json:"code"`
+	Details  any                       `json:"details,omitempty"`
+	Errors   []problemViolationPayload `json:"errors,omitempty"`
+}
 
-	if appErr, ok := err.(*apperror.Error); ok {
-		errorType = appErr.ErrorType
-		description = appErr.String()
+type problemViolationPayload struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func errorResponse(ctx *Context, err error) *Response {
+	appErr, ok := err.(*apperror.Error)
+	if !ok {
+		appErr = &apperror.Error{
+			ErrorType:   apperror.UnexpectedError,
+			Description: err.Error(),
+		}
 	}
 
-	statusCode := errorType.StatusCode()
+	statusCode := appErr.ErrorType.StatusCode()
 
-	resp := Response{
-		Status: statusCode,
-		Payload: errorPayload{
+	if acceptsLegacyErrors(ctx.Request) {
+		resp := Response{
 			Status:      statusCode,
-			ErrorCode:   errorType.ErrorCode(),
-			Description: description,
-		},
+			ContentType: jsonContentType,
+			Payload:     legacyPayload(appErr),
+		}
+
+		return &resp
+	}
+
+	resp := Response{
+		Status:      statusCode,
+		ContentType: problemContentType,
+		Payload:     problem(appErr, ctx.Request.URL.Path),
 	}
 
 	return &resp
 }
 
+func legacyPayload(err *apperror.Error) errorPayload {
+	payload := errorPayload{
+		Status:      err.ErrorType.StatusCode(),
+		ErrorCode:   err.ErrorType.ErrorCode(),
+		Description: err.String(),
+		Details:     err.Details,
+	}
+
+	for _, violation := range err.Violations {
+		payload.Errors = append(payload.Errors, violationPayload{
+			Field:   violation.Field,
+			Code:    violation.ErrorType.ErrorCode(),
+			Message: violation.Message,
+		})
+	}
+
+	return payload
+}
+
+func problem(err *apperror.Error, instance string) problemPayload {
+	payload := problemPayload{
+		Type:     problemTypePrefix + err.ErrorType.ID(),
+		Title:    err.ErrorType.Title(),
+		Status:   err.ErrorType.StatusCode(),
+		Detail:   err.String(),
+		Instance: instance,
+		Code:     err.ErrorType.ID(),
+		Details:  err.Details,
+	}
+
+	for _, violation := range err.Violations {
+		payload.Errors = append(payload.Errors, problemViolationPayload{
+			Field:   violation.Field,
+			Code:    violation.ErrorType.ID(),
+			Message: violation.Message,
+		})
+	}
+
+	return payload
+}
+
+// acceptsLegacyErrors is true when the client accepts application/json, without accepting application/problem+json
+func acceptsLegacyErrors(req *http.Request) bool {
+	legacy := false
+
+	for _, header := range req.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(header, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+
+			switch strings.TrimSpace(strings.ToLower(mediaType)) {
+			case problemContentType:
+				return false
+			case jsonContentType:
+				legacy = true
+			}
+		}
+	}
+
+	return legacy
+}
+
 func writeResponse(ctx *Context, resp *Response) {
-	ctx.Writer.WriteHeader(resp.Status)
+	switch {
+	case resp.Stream != nil:
+		writeStream(ctx, resp)
+	case resp.Payload != nil:
+		contentType := resp.ContentType
+		if len(contentType) == 0 {
+			contentType = jsonContentType
+		}
 
-	if resp.Payload != nil {
-		ctx.Writer.Header().Set("Content-Type", "application/json")
+		ctx.Writer.Header().Set("Content-Type", contentType)
+		ctx.Writer.WriteHeader(resp.Status)
 
 		err := json.NewEncoder(ctx.Writer).Encode(resp.Payload)
 		if err != nil {
 			log.Printf("Error writing payload %v to response\n", resp.Payload)
 		}
+	default:
+		ctx.Writer.WriteHeader(resp.Status)
 	}
 
 	log.Printf("%d: %s: %v\n", resp.Status, ctx.fullRequestURI(), ctx.duration())
 }
+
+func writeStream(ctx *Context, resp *Response) {
+	controller := http.NewResponseController(ctx.Writer)
+
+	ctx.Writer.Header().Set("Content-Type", resp.ContentType)
+	ctx.Writer.WriteHeader(resp.Status)
+
+	err := resp.Stream(ctx.Writer, controller.Flush)
+	if err != nil {
+		log.Printf("Error writing stream to response %v: %v\n", ctx.fullRequestURI(), err)
+	}
+}
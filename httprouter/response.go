@@ -1,16 +1,21 @@
 package httprouter
 
 import (
+	"io"
 	"log"
 	"net/http"
 	"time"
 )
 
 type Response struct {
-	Status  int
-	Payload any
+	Status      int
+	Payload     any
+	ContentType string
+	Stream      StreamFunc
 }
 
+type StreamFunc func(w io.Writer, flush func() error) error
+
 type RequestHandler func(*Context) (*Response, error)
 
 func (h RequestHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -23,7 +28,7 @@ func (h RequestHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	resp, err := h(ctx)
 	if err != nil {
 		log.Printf("ERROR => %s => %v", ctx.fullRequestURI(), err.Error())
-		resp = errorResponse(err)
+		resp = errorResponse(ctx, err)
 	}
 
 	writeResponse(ctx, resp)
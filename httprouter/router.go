@@ -1,9 +1,18 @@
 package httprouter
 
-import "net/http"
+import (
+	"net/http"
+	"slices"
+)
+
+type Route struct {
+	Method string
+	Path   string
+}
 
 type Router struct {
-	mux *http.ServeMux
+	mux    *http.ServeMux
+	routes []Route
 }
 
 func New() *Router {
@@ -19,17 +28,31 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 func (r *Router) GET(path string, handler RequestHandler) {
-	r.mux.Handle("GET "+path, handler)
+	r.handle(http.MethodGet, path, handler)
 }
 
 func (r *Router) POST(path string, handler RequestHandler) {
-	r.mux.Handle("POST "+path, handler)
+	r.handle(http.MethodPost, path, handler)
 }
 
 func (r *Router) DELETE(path string, handler RequestHandler) {
-	r.mux.Handle("DELETE "+path, handler)
+	r.handle(http.MethodDelete, path, handler)
 }
 
 func (r *Router) PUT(path string, handler RequestHandler) {
-	r.mux.Handle("PUT "+path, handler)
+	r.handle(http.MethodPut, path, handler)
+}
+
+func (r *Router) PATCH(path string, handler RequestHandler) {
+	r.handle(http.MethodPatch, path, handler)
+}
+
+// Routes returns the registered routes, in the order they were registered
+func (r *Router) Routes() []Route {
+	return slices.Clone(r.routes)
+}
+
+func (r *Router) handle(method string, path string, handler RequestHandler) {
+	r.mux.Handle(method+" "+path, handler)
+	r.routes = append(r.routes, Route{Method: method, Path: path})
 }
@@ -0,0 +1,127 @@
+package model
+
+import "net/url"
+
+type AggregateFunction string
+
+const (
+	CountFunction AggregateFunction = "count"
+	SumFunction   AggregateFunction = "sum"
+	MinFunction   AggregateFunction = "min"
+	MaxFunction   AggregateFunction = "max"
+	AvgFunction   AggregateFunction = "avg"
+)
+
+const GroupByParameter = "groupBy"
+
+var aggregateFunctions = []AggregateFunction{CountFunction, SumFunction, MinFunction, MaxFunction, AvgFunction}
+
+func (f AggregateFunction) Supports(dataType DataType) bool {
+	switch f {
+	case SumFunction, AvgFunction:
+		return dataType.IsNumeric()
+	case MinFunction, MaxFunction:
+		return dataType.IsOrdered()
+	}
+
+	return false
+}
+
+type Aggregate struct {
+	Function AggregateFunction
+	Field    string
+}
+
+type Aggregation struct {
+	Criteria   Criteria
+	Count      bool
+	Aggregates []Aggregate
+	GroupBy    []string
+}
+
+type AggregateResult struct {
+	Group Object         `json:"group,omitempty"`
+	Count *int64         `json:"count,omitempty"`
+	Sum   map[string]any `json:"sum,omitempty"`
+	Min   map[string]any `json:"min,omitempty"`
+	Max   map[string]any `json:"max,omitempty"`
+	Avg   map[string]any `json:"avg,omitempty"`
+}
+
+// Set stores the value of a field aggregate on the result
+func (r *AggregateResult) Set(aggregate Aggregate, value any) {
+	var values *map[string]any
+
+	switch aggregate.Function {
+	case SumFunction:
+		values = &r.Sum
+	case MinFunction:
+		values = &r.Min
+	case MaxFunction:
+		values = &r.Max
+	case AvgFunction:
+		values = &r.Avg
+	default:
+		return
+	}
+
+	if *values == nil {
+		*values = make(map[string]any)
+	}
+
+	(*values)[aggregate.Field] = value
+}
+
+// AggregationCriteria returns the query parameters without the aggregation ones
+func AggregationCriteria(parameters url.Values) url.Values {
+	names := []string{GroupByParameter}
+	for _, function := range aggregateFunctions {
+		names = append(names, string(function))
+	}
+
+	return removeParameters(parameters, names)
+}
+
+// ParseAggregates reads the field aggregates, e.g. sum=age,salary&max=age
+func ParseAggregates(parameters url.Values) []Aggregate {
+	aggregates := make([]Aggregate, 0)
+
+	for _, function := range aggregateFunctions {
+		if function == CountFunction {
+			continue
+		}
+
+		for _, field := range splitList(parameters[string(function)]) {
+			aggregate := Aggregate{
+				Function: function,
+				Field:    field,
+			}
+
+			aggregates = append(aggregates, aggregate)
+		}
+	}
+
+	return aggregates
+}
+
+func ParseGroupBy(parameters url.Values) []string {
+	return splitList(parameters[GroupByParameter])
+}
+
+func NewAggregation(parameters url.Values, schema []Field) (Aggregation, error) {
+	criteria, err := Convert(AggregationCriteria(parameters), schema)
+	if err != nil {
+		return Aggregation{}, err
+	}
+
+	aggregates := ParseAggregates(parameters)
+
+	aggregation := Aggregation{
+		Criteria:   criteria,
+		Count:      parameters.Has(string(CountFunction)) || len(aggregates) == 0,
+		Aggregates: aggregates,
+		GroupBy:    ParseGroupBy(parameters),
+	}
+
+	return aggregation, nil
+}
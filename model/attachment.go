@@ -0,0 +1,15 @@
+package model
+
+import "io"
+
+// MaxAttachmentSize is the maximum number of bytes of an attachment
+const MaxAttachmentSize = 10 << 20
+
+const DefaultAttachmentType = "application/octet-stream"
+
+// Attachment content is streamed, it's read while stored or written
+type Attachment struct {
+	Name        string
+	ContentType string
+	Content     io.Reader
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type Change struct {
+	Sequence int64         `json:"seq"`
+	Type     OperationType `json:"op"`
+	Key      string        `json:"key"`
+	Version  int64         `json:"version"`
+	Value    Object        `json:"value,omitempty"`
+	At       time.Time     `json:"at"`
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+type Constraint string
+
+const (
+	MinConstraint       Constraint = "min"
+	MaxConstraint       Constraint = "max"
+	MinLengthConstraint Constraint = "minLength"
+	MaxLengthConstraint Constraint = "maxLength"
+	PatternConstraint   Constraint = "pattern"
+	DefaultConstraint   Constraint = "default"
+)
+
+// patterns caches the compiled patterns of the fields, so they are compiled once and not on every value
+var patterns sync.Map
+
+// Supports checks if the constraint can be used on fields of the data type
+func (c Constraint) Supports(dataType DataType) bool {
+	switch c {
+	case MinConstraint, MaxConstraint:
+		return dataType.IsNumeric()
+	case MinLengthConstraint, MaxLengthConstraint:
+		return dataType == StringDataType || dataType == ArrayDataType || dataType == BinaryDataType
+	case PatternConstraint:
+		return dataType == StringDataType
+	case DefaultConstraint:
+		return true
+	}
+
+	return false
+}
+
+// Constraints returns the constraints declared on the field
+func (f Field) Constraints() []Constraint {
+	constraints := make([]Constraint, 0)
+
+	if f.Min != nil {
+		constraints = append(constraints, MinConstraint)
+	}
+
+	if f.Max != nil {
+		constraints = append(constraints, MaxConstraint)
+	}
+
+	if f.MinLength != nil {
+		constraints = append(constraints, MinLengthConstraint)
+	}
+
+	if f.MaxLength != nil {
+		constraints = append(constraints, MaxLengthConstraint)
+	}
+
+	if len(f.Pattern) > 0 {
+		constraints = append(constraints, PatternConstraint)
+	}
+
+	if f.Default != nil {
+		constraints = append(constraints, DefaultConstraint)
+	}
+
+	return constraints
+}
+
+// Violation returns the first constraint the value doesn't satisfy, values must have the field type
+func (f Field) Violation(value any) (Constraint, bool) {
+	if number, ok := toFloat(value); ok {
+		if f.Min != nil && number < *f.Min {
+			return MinConstraint, true
+		}
+
+		if f.Max != nil && number > *f.Max {
+			return MaxConstraint, true
+		}
+	}
+
+	length := -1
+
+	switch v := value.(type) {
+	case string:
+		length = utf8.RuneCountInString(v)
+	case []any:
+		length = len(v)
+	}
+
+	// the length of binary values is their number of bytes
+	if content, ok := toBytes(value); ok && f.Type == BinaryDataType {
+		length = len(content)
+	}
+
+	if length >= 0 {
+		if f.MinLength != nil && length < *f.MinLength {
+			return MinLengthConstraint, true
+		}
+
+		if f.MaxLength != nil && length > *f.MaxLength {
+			return MaxLengthConstraint, true
+		}
+	}
+
+	if text, ok := value.(string); ok && len(f.Pattern) > 0 && f.Type == StringDataType {
+		if !f.matchPattern(text) {
+			return PatternConstraint, true
+		}
+	}
+
+	return "", false
+}
+
+func (f Field) matchPattern(text string) bool {
+	cached, found := patterns.Load(f.Pattern)
+	if !found {
+		pattern, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return false
+		}
+
+		cached, _ = patterns.LoadOrStore(f.Pattern, pattern)
+	}
+
+	return cached.(*regexp.Regexp).MatchString(text)
+}
+
+// WithDefaults returns a copy of the object with the default values of the missing fields,
+// defaults are also applied to the nested fields of objects
+func (o Object) WithDefaults(schema []Field) Object {
+	if o == nil {
+		return nil
+	}
+
+	output := make(Object, len(o))
+	for name, value := range o {
+		output[name] = value
+	}
+
+	for _, field := range schema {
+		value, found := output[field.Name]
+
+		switch {
+		case !found && field.Default != nil:
+			output[field.Name] = field.Default
+		case found && field.Type == ObjectDataType:
+			if nested, ok := value.(map[string]any); ok {
+				output[field.Name] = map[string]any(Object(nested).WithDefaults(field.Fields))
+			}
+		}
+	}
+
+	return output
+}
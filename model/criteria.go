@@ -1,25 +1,96 @@
 package model
 
 import (
+	"fmt"
 	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 )
 
-type Criteria map[string][]any
+type Operator string
+
+const (
+	EqualOperator          Operator = "eq"
+	NotEqualOperator       Operator = "ne"
+	GreaterOperator        Operator = "gt"
+	GreaterOrEqualOperator Operator = "gte"
+	LessOperator           Operator = "lt"
+	LessOrEqualOperator    Operator = "lte"
+	PrefixOperator         Operator = "prefix"
+	NullOperator           Operator = "null"
+	InOperator             Operator = "in"
+	ExistsOperator         Operator = "exists"
+	ContainsOperator       Operator = "contains"
+)
+
+var parameterRegExp = regexp.MustCompile(`^([^\[\]]+)(?:\[([^\[\]]*)\])?$`)
+
+func (o Operator) Supports(dataType DataType) bool {
+	switch o {
+	case NullOperator, ExistsOperator:
+		return true
+	case EqualOperator, NotEqualOperator, InOperator:
+		return !dataType.IsJSON()
+	case ContainsOperator:
+		return dataType == ArrayDataType
+	case GreaterOperator, GreaterOrEqualOperator, LessOperator, LessOrEqualOperator:
+		return dataType.IsOrdered()
+	case PrefixOperator:
+		return dataType == StringDataType || dataType == DynamicDataType
+	}
+
+	return false
+}
+
+type Criterion struct {
+	Field    string
+	Operator Operator
+	Values   []any
+}
+
+type Criteria []Criterion
+
+// ParseParameter splits a query parameter like age[gt] into field and operator
+func ParseParameter(parameter string) (string, Operator, bool) {
+	matches := parameterRegExp.FindStringSubmatch(parameter)
+	if matches == nil {
+		return "", "", false
+	}
+
+	operator := Operator(matches[2])
+	if len(operator) == 0 {
+		operator = EqualOperator
+	}
+
+	return matches[1], operator, true
+}
 
 func Convert(criteria url.Values, schema []Field) (Criteria, error) {
-	output := make(Criteria)
+	parameters := make([]string, 0, len(criteria))
+	for parameter := range criteria {
+		parameters = append(parameters, parameter)
+	}
+
+	slices.Sort(parameters)
 
-	for _, field := range schema {
-		values, found := criteria[field.Name]
+	output := make(Criteria, 0, len(parameters))
+
+	for _, parameter := range parameters {
+		name, operator, ok := ParseParameter(parameter)
+		if !ok {
+			continue
+		}
 
+		field, found := FieldByPath(schema, name)
 		if !found {
 			continue
 		}
 
 		options := make([]any, 0)
 
-		for _, value := range values {
-			converted, err := field.Type.Convert(value)
+		for _, value := range criteria[parameter] {
+			converted, err := operator.Convert(field, value)
 			if err != nil {
 				return nil, err
 			}
@@ -27,8 +98,36 @@ func Convert(criteria url.Values, schema []Field) (Criteria, error) {
 			options = append(options, converted)
 		}
 
-		output[field.Name] = options
+		// undeclared fields of documents are read from the document field
+		if field.Type == DynamicDataType {
+			name = field.Name
+		}
+
+		criterion := Criterion{
+			Field:    name,
+			Operator: operator,
+			Values:   options,
+		}
+
+		output = append(output, criterion)
 	}
 
 	return output, nil
 }
+
+func (o Operator) Convert(field Field, value string) (any, error) {
+	switch o {
+	case NullOperator, ExistsOperator:
+		return strconv.ParseBool(value)
+	case PrefixOperator:
+		return value, nil
+	case ContainsOperator:
+		if field.Items == nil {
+			return nil, fmt.Errorf("field %v has no items", field.Name)
+		}
+
+		return field.Items.Type.Convert(value)
+	}
+
+	return field.Type.Convert(value)
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+)
+
+// Cursor marks the position of the last row of a page, it holds the values of the sort fields followed by the key
+type Cursor struct {
+	Sort  []string `json:"s"`
+	After []any    `json:"a"`
+}
+
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+func NewCursor(sort []SortField, after []any) Cursor {
+	return Cursor{
+		Sort:  sortNames(sort),
+		After: after,
+	}
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (c Cursor) Matches(sort []SortField) bool {
+	return slices.Equal(c.Sort, sortNames(sort)) && len(c.After) == len(sort)+1
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	var cursor Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(&cursor); err != nil {
+		return cursor, err
+	}
+
+	// integers must be kept exact
+	for i, position := range cursor.After {
+		if number, ok := position.(json.Number); ok {
+			if integer, err := number.Int64(); err == nil {
+				cursor.After[i] = integer
+			} else {
+				cursor.After[i], _ = number.Float64()
+			}
+		}
+	}
+
+	return cursor, nil
+}
+
+func sortNames(sort []SortField) []string {
+	names := make([]string, 0, len(sort))
+
+	for _, field := range sort {
+		if field.Descending {
+			names = append(names, "-"+field.Field)
+		} else {
+			names = append(names, field.Field)
+		}
+	}
+
+	return names
+}
@@ -1,15 +1,55 @@
 package model
 
-import "strconv"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+)
 
 type DataType string
 
 const (
-	StringDataType DataType = "string"
-	NumberDataType DataType = "number"
-	BoolDataType   DataType = "bool"
+	StringDataType    DataType = "string"
+	NumberDataType    DataType = "number"
+	BoolDataType      DataType = "bool"
+	IntegerDataType   DataType = "integer"
+	TimestampDataType DataType = "timestamp"
+	DateDataType      DataType = "date"
+	EnumDataType      DataType = "enum"
+	ObjectDataType    DataType = "object"
+	ArrayDataType     DataType = "array"
+	BinaryDataType    DataType = "binary"
+	// DynamicDataType is the type of undeclared fields of document buckets, it can't be used on schemas
+	DynamicDataType DataType = "dynamic"
 )
 
+const (
+	// TimestampLayout is used to store timestamps, in UTC with a fixed length, so they sort as strings
+	TimestampLayout = "2006-01-02T15:04:05.000Z"
+	DateLayout      = "2006-01-02"
+)
+
+// IsJSON returns true for types stored as JSON
+func (d DataType) IsJSON() bool {
+	return d == ObjectDataType || d == ArrayDataType
+}
+
+func (d DataType) IsNumeric() bool {
+	return d == NumberDataType || d == IntegerDataType
+}
+
+// IsOrdered returns true for types that can be compared with less and greater than
+func (d DataType) IsOrdered() bool {
+	switch d {
+	case StringDataType, NumberDataType, IntegerDataType, TimestampDataType, DateDataType, DynamicDataType:
+		return true
+	}
+
+	return false
+}
+
 func (d DataType) Convert(value string) (any, error) {
 	switch d {
 	case NumberDataType:
@@ -26,6 +66,30 @@ func (d DataType) Convert(value string) (any, error) {
 		}
 
 		return converted, nil
+	case IntegerDataType:
+		converted, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		return converted, nil
+	case TimestampDataType:
+		converted, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return converted.UTC().Format(TimestampLayout), nil
+	case DateDataType:
+		if _, err := time.Parse(DateLayout, value); err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	case BinaryDataType:
+		return base64.StdEncoding.DecodeString(value)
+	case DynamicDataType:
+		return dynamicValue(value), nil
 	}
 
 	return value, nil
@@ -33,16 +97,163 @@ func (d DataType) Convert(value string) (any, error) {
 
 func (d DataType) ValidValue(value any) bool {
 	switch d {
-	case StringDataType:
+	case StringDataType, EnumDataType:
 		_, ok := value.(string)
 		return ok
 	case NumberDataType:
-		_, ok := value.(float64)
+		_, ok := toFloat(value)
 		return ok
 	case BoolDataType:
 		_, ok := value.(bool)
 		return ok
+	case IntegerDataType:
+		_, ok := toInteger(value)
+		return ok
+	case TimestampDataType, DateDataType:
+		text, ok := value.(string)
+		if !ok {
+			return false
+		}
+
+		_, err := d.Convert(text)
+		return err == nil
+	case ObjectDataType:
+		_, ok := value.(map[string]any)
+		return ok
+	case ArrayDataType:
+		_, ok := value.([]any)
+		return ok
+	case BinaryDataType:
+		_, ok := toBytes(value)
+		return ok
+	case DynamicDataType:
+		switch value.(type) {
+		case string, bool:
+			return true
+		}
+
+		_, ok := toFloat(value)
+		return ok
 	}
 
 	return false
 }
+
+// Normalize converts a valid value to the representation used to store and compare values
+func (d DataType) Normalize(value any) any {
+	switch d {
+	case NumberDataType:
+		if converted, ok := toFloat(value); ok {
+			return converted
+		}
+	case IntegerDataType:
+		if converted, ok := toInteger(value); ok {
+			return converted
+		}
+	case TimestampDataType:
+		if text, ok := value.(string); ok {
+			if converted, err := d.Convert(text); err == nil {
+				return converted
+			}
+		}
+	case BinaryDataType:
+		if converted, ok := toBytes(value); ok {
+			return converted
+		}
+	case DynamicDataType:
+		return normalizeDynamic(value)
+	}
+
+	return value
+}
+
+// normalizeDynamic converts the numbers of undeclared values, including the ones nested on objects and arrays
+func normalizeDynamic(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if converted, err := v.Int64(); err == nil {
+			return converted
+		}
+
+		converted, _ := v.Float64()
+		return converted
+	case map[string]any:
+		normalized := make(map[string]any, len(v))
+		for name, nested := range v {
+			normalized[name] = normalizeDynamic(nested)
+		}
+
+		return normalized
+	case []any:
+		normalized := make([]any, 0, len(v))
+		for _, item := range v {
+			normalized = append(normalized, normalizeDynamic(item))
+		}
+
+		return normalized
+	}
+
+	return value
+}
+
+// dynamicValue converts text to the type it looks like, as the type of undeclared fields isn't known
+func dynamicValue(value string) any {
+	if converted, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return converted
+	}
+
+	if converted, err := strconv.ParseFloat(value, 64); err == nil {
+		return converted
+	}
+
+	if converted, err := strconv.ParseBool(value); err == nil {
+		return converted
+	}
+
+	return value
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		converted, err := v.Float64()
+		return converted, err == nil
+	}
+
+	return 0, false
+}
+
+func toInteger(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return 0, false
+		}
+
+		return int64(v), true
+	case json.Number:
+		converted, err := v.Int64()
+		return converted, err == nil
+	}
+
+	return 0, false
+}
+
+// toBytes accepts binary values already decoded or encoded using base64, as they are on JSON
+func toBytes(value any) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		converted, err := base64.StdEncoding.DecodeString(v)
+		return converted, err == nil
+	}
+
+	return nil, false
+}
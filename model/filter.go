@@ -0,0 +1,125 @@
+package model
+
+type ResultType string
+
+const (
+	KeysResult    ResultType = "keys"
+	ObjectsResult ResultType = "objects"
+)
+
+// Filter is a node of a filter tree, it's either a logical node (and, or, not) or a comparison with a field
+type Filter struct {
+	And      []Filter `json:"and,omitempty"`
+	Or       []Filter `json:"or,omitempty"`
+	Not      *Filter  `json:"not,omitempty"`
+	Field    string   `json:"field,omitempty"`
+	Operator Operator `json:"op,omitempty"`
+	Value    any      `json:"value,omitempty"`
+}
+
+// Comparison returns the comparison operator, eq by default
+func (f *Filter) Comparison() Operator {
+	if len(f.Operator) == 0 {
+		return EqualOperator
+	}
+
+	return f.Operator
+}
+
+// Values returns the values to compare with the field, in accepts a list of values
+func (f *Filter) Values() []any {
+	if list, ok := f.Value.([]any); ok {
+		return list
+	}
+
+	return []any{f.Value}
+}
+
+// Normalize returns a copy of the filter with the values converted to the representation of their field types
+func (f Filter) Normalize(schema []Field) Filter {
+	switch {
+	case f.And != nil:
+		f.And = normalizeFilters(f.And, schema)
+	case f.Or != nil:
+		f.Or = normalizeFilters(f.Or, schema)
+	case f.Not != nil:
+		not := f.Not.Normalize(schema)
+		f.Not = &not
+	default:
+		field, found := FieldByPath(schema, f.Field)
+		if !found {
+			break
+		}
+
+		if field.Type == DynamicDataType {
+			f.Field = field.Name
+		}
+
+		if f.Comparison() == ContainsOperator && field.Items != nil {
+			field = *field.Items
+		}
+
+		if list, ok := f.Value.([]any); ok {
+			values := make([]any, 0, len(list))
+			for _, value := range list {
+				values = append(values, field.Normalize(value))
+			}
+
+			f.Value = values
+		} else {
+			f.Value = field.Normalize(f.Value)
+		}
+	}
+
+	return f
+}
+
+func normalizeFilters(filters []Filter, schema []Field) []Filter {
+	normalized := make([]Filter, 0, len(filters))
+	for _, filter := range filters {
+		normalized = append(normalized, filter.Normalize(schema))
+	}
+
+	return normalized
+}
+
+type SearchRequest struct {
+	Filter *Filter    `json:"filter,omitempty"`
+	Fields []string   `json:"fields,omitempty"`
+	Sort   []string   `json:"sort,omitempty"`
+	Limit  int        `json:"limit,omitempty"`
+	Cursor string     `json:"cursor,omitempty"`
+	Return ResultType `json:"return,omitempty"`
+}
+
+func (r *SearchRequest) SortOrder() []SortField {
+	return parseSortList(r.Sort)
+}
+
+func (r *SearchRequest) Query(schema []Field) (Query, error) {
+	query := Query{
+		Fields: r.Fields,
+		Sort:   r.SortOrder(),
+		Limit:  r.Limit,
+	}
+
+	if r.Filter != nil {
+		filter := r.Filter.Normalize(schema)
+		query.Filter = &filter
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultLimit
+	}
+
+	if len(r.Cursor) > 0 {
+		cursor, err := DecodeCursor(r.Cursor)
+		if err != nil {
+			return Query{}, err
+		}
+
+		query.After = cursor.After
+	}
+
+	return query, nil
+}
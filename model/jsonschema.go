@@ -0,0 +1,318 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchemaDialects are the JSON Schema versions accepted on bucket definitions,
+// the keywords supported have the same meaning on all of them
+var JSONSchemaDialects = []string{
+	JSONSchemaDialect,
+	"https://json-schema.org/draft/2019-09/schema",
+	"http://json-schema.org/draft-07/schema",
+}
+
+var jsonSchemaKeywords = []string{
+	"$schema", "$id", "title", "description", "type", "format", "contentEncoding", "enum",
+	"properties", "required", "additionalProperties", "items",
+	"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems", "pattern", "default",
+}
+
+// JSONSchema is the subset of JSON Schema that can be translated to and from the fields of a bucket
+type JSONSchema struct {
+	Dialect              string         `json:"$schema,omitempty"`
+	ID                   string         `json:"$id,omitempty"`
+	Title                string         `json:"title,omitempty"`
+	Description          string         `json:"description,omitempty"`
+	Type                 any            `json:"type,omitempty"`
+	Format               string         `json:"format,omitempty"`
+	ContentEncoding      string         `json:"contentEncoding,omitempty"`
+	Enum                 []any          `json:"enum,omitempty"`
+	Properties           JSONProperties `json:"properties,omitempty"`
+	Required             []string       `json:"required,omitempty"`
+	AdditionalProperties *bool          `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema    `json:"items,omitempty"`
+	Minimum              *float64       `json:"minimum,omitempty"`
+	Maximum              *float64       `json:"maximum,omitempty"`
+	MinLength            *int           `json:"minLength,omitempty"`
+	MaxLength            *int           `json:"maxLength,omitempty"`
+	MinItems             *int           `json:"minItems,omitempty"`
+	MaxItems             *int           `json:"maxItems,omitempty"`
+	Pattern              string         `json:"pattern,omitempty"`
+	Default              any            `json:"default,omitempty"`
+	// Ref and OneOf are only used on generated documents, they aren't accepted on bucket definitions
+	Ref   string       `json:"$ref,omitempty"`
+	OneOf []JSONSchema `json:"oneOf,omitempty"`
+	// Unsupported are the keywords of an imported schema that can't be translated to fields
+	Unsupported []string `json:"-"`
+}
+
+// JSONProperties keep the properties of an object in the order of the fields
+type JSONProperties []JSONProperty
+
+type JSONProperty struct {
+	Name   string
+	Schema JSONSchema
+}
+
+// NewJSONSchema describes the values of a bucket, only document buckets accept additional properties
+func NewJSONSchema(name string, schema []Field, options BucketOptions) JSONSchema {
+	document := objectSchema(schema)
+	document.Dialect = JSONSchemaDialect
+	document.Title = name
+
+	additional := options.IsDocument()
+	document.AdditionalProperties = &additional
+
+	return document
+}
+
+func objectSchema(fields []Field) JSONSchema {
+	additional := false
+	schema := JSONSchema{Type: "object", AdditionalProperties: &additional}
+
+	for _, field := range fields {
+		schema.Properties = append(schema.Properties, JSONProperty{Name: field.Name, Schema: field.JSONSchema()})
+
+		if field.Required {
+			schema.Required = append(schema.Required, field.Name)
+		}
+	}
+
+	return schema
+}
+
+// JSONSchema describes the values of the field, lengths of binary values can't be described as they are counted in bytes
+func (f Field) JSONSchema() JSONSchema {
+	var schema JSONSchema
+
+	switch f.Type {
+	case StringDataType:
+		schema.Type = "string"
+		schema.MinLength, schema.MaxLength = f.MinLength, f.MaxLength
+	case NumberDataType:
+		schema.Type = "number"
+	case IntegerDataType:
+		schema.Type = "integer"
+	case BoolDataType:
+		schema.Type = "boolean"
+	case TimestampDataType:
+		schema.Type, schema.Format = "string", "date-time"
+	case DateDataType:
+		schema.Type, schema.Format = "string", "date"
+	case EnumDataType:
+		schema.Type = "string"
+		for _, value := range f.Values {
+			schema.Enum = append(schema.Enum, value)
+		}
+	case BinaryDataType:
+		schema.Type, schema.ContentEncoding = "string", "base64"
+	case ObjectDataType:
+		schema = objectSchema(f.Fields)
+	case ArrayDataType:
+		schema.Type = "array"
+		schema.MinItems, schema.MaxItems = f.MinLength, f.MaxLength
+
+		if f.Items != nil {
+			items := f.Items.JSONSchema()
+			schema.Items = &items
+		}
+	}
+
+	schema.Minimum, schema.Maximum = f.Min, f.Max
+	schema.Pattern = f.Pattern
+	schema.Default = f.Default
+
+	return schema
+}
+
+// DataType is the field type matching the type, format and encoding of the schema, empty when there is none
+func (s JSONSchema) DataType() DataType {
+	// a list of types, e.g. ["string", "null"], has no matching field type
+	typeName, _ := s.Type.(string)
+
+	switch typeName {
+	case "string":
+		switch {
+		case len(s.Enum) > 0 && len(s.Format) == 0 && len(s.ContentEncoding) == 0:
+			return EnumDataType
+		case len(s.Enum) > 0:
+			return ""
+		case s.ContentEncoding == "base64" && len(s.Format) == 0:
+			return BinaryDataType
+		case len(s.ContentEncoding) > 0:
+			return ""
+		case s.Format == "date-time":
+			return TimestampDataType
+		case s.Format == "date":
+			return DateDataType
+		case len(s.Format) == 0:
+			return StringDataType
+		}
+	case "number":
+		return NumberDataType
+	case "integer":
+		return IntegerDataType
+	case "boolean":
+		return BoolDataType
+	case "object":
+		return ObjectDataType
+	case "array":
+		return ArrayDataType
+	}
+
+	return ""
+}
+
+// Mode is the mode of a bucket defined by the schema, additional properties are only accepted by document buckets
+func (s JSONSchema) Mode() BucketMode {
+	if s.AdditionalProperties != nil && *s.AdditionalProperties {
+		return DocumentMode
+	}
+
+	return SchemaMode
+}
+
+// Fields translates the properties of an object schema, the schema must be valid
+func (s JSONSchema) Fields() []Field {
+	fields := make([]Field, 0, len(s.Properties))
+
+	for _, property := range s.Properties {
+		field := property.Schema.field()
+		field.Name = property.Name
+		field.Required = slices.Contains(s.Required, property.Name)
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+func (s JSONSchema) field() Field {
+	field := Field{
+		Type:    s.DataType(),
+		Min:     s.Minimum,
+		Max:     s.Maximum,
+		Pattern: s.Pattern,
+		Default: s.Default,
+	}
+
+	switch field.Type {
+	case EnumDataType:
+		for _, value := range s.Enum {
+			text, _ := value.(string)
+			field.Values = append(field.Values, text)
+		}
+	case ObjectDataType:
+		field.Fields = s.Fields()
+	case ArrayDataType:
+		if s.Items != nil {
+			items := s.Items.field()
+			field.Items = &items
+		}
+
+		field.MinLength, field.MaxLength = s.MinItems, s.MaxItems
+	default:
+		field.MinLength, field.MaxLength = s.MinLength, s.MaxLength
+	}
+
+	return field
+}
+
+// SupportedDialect is true when the schema doesn't declare its version or declares one of the known versions
+func (s JSONSchema) SupportedDialect() bool {
+	return len(s.Dialect) == 0 || slices.Contains(JSONSchemaDialects, strings.TrimSuffix(s.Dialect, "#"))
+}
+
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	type plain JSONSchema
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	for keyword := range keywords {
+		if !slices.Contains(jsonSchemaKeywords, keyword) {
+			s.Unsupported = append(s.Unsupported, keyword)
+		}
+	}
+
+	slices.Sort(s.Unsupported)
+
+	return nil
+}
+
+func (p JSONProperties) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+
+	for i, property := range p {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(name)
+		buffer.WriteByte(':')
+		buffer.Write(schema)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON reads the properties in the order they are declared, so fields keep that order
+func (p *JSONProperties) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if token != json.Delim('{') {
+		return errors.New("properties must be an object")
+	}
+
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		var schema JSONSchema
+		if err := decoder.Decode(&schema); err != nil {
+			return err
+		}
+
+		*p = append(*p, JSONProperty{Name: token.(string), Schema: schema})
+	}
+
+	_, err = decoder.Token()
+	return err
+}
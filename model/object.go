@@ -1,3 +1,79 @@
 package model
 
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
 type Object map[string]any
+
+// Normalize returns a copy of the object with the values converted to the representation of their field types
+func (o Object) Normalize(schema []Field) Object {
+	if o == nil {
+		return nil
+	}
+
+	normalized := make(Object, len(o))
+
+	for name, value := range o {
+		if field, found := FieldByName(schema, name); found {
+			value = field.Normalize(value)
+		} else {
+			value = DynamicDataType.Normalize(value)
+		}
+
+		normalized[name] = value
+	}
+
+	return normalized
+}
+
+// MergePatch returns a copy of the object with a JSON merge patch (RFC 7386) applied,
+// null values remove fields and objects are merged recursively
+func (o Object) MergePatch(patch Object) Object {
+	return Object(mergePatch(o, patch))
+}
+
+func mergePatch(target map[string]any, patch map[string]any) map[string]any {
+	merged := make(map[string]any, len(target))
+	maps.Copy(merged, target)
+
+	for name, value := range patch {
+		if value == nil {
+			delete(merged, name)
+			continue
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			current, _ := merged[name].(map[string]any)
+			merged[name] = mergePatch(current, nested)
+			continue
+		}
+
+		merged[name] = value
+	}
+
+	return merged
+}
+
+// ChangedFields returns the sorted names of the fields with different values on both objects
+func (o Object) ChangedFields(other Object) []string {
+	changed := make([]string, 0)
+
+	for name, value := range o {
+		if otherValue, found := other[name]; !found || !reflect.DeepEqual(value, otherValue) {
+			changed = append(changed, name)
+		}
+	}
+
+	for name := range other {
+		if _, found := o[name]; !found {
+			changed = append(changed, name)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+// cases from the examples of RFC 7386
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target   Object
+		patch    Object
+		expected Object
+	}{
+		{target: Object{"a": "b"}, patch: Object{"a": "c"}, expected: Object{"a": "c"}},
+		{target: Object{"a": "b"}, patch: Object{"b": "c"}, expected: Object{"a": "b", "b": "c"}},
+		{target: Object{"a": "b"}, patch: Object{"a": nil}, expected: Object{}},
+		{target: Object{"a": "b", "b": "c"}, patch: Object{"a": nil}, expected: Object{"b": "c"}},
+		{target: Object{"a": []any{"b"}}, patch: Object{"a": "c"}, expected: Object{"a": "c"}},
+		{target: Object{"a": "c"}, patch: Object{"a": []any{"b"}}, expected: Object{"a": []any{"b"}}},
+		{
+			target:   Object{"a": map[string]any{"b": "c"}},
+			patch:    Object{"a": map[string]any{"b": "d", "c": nil}},
+			expected: Object{"a": map[string]any{"b": "d"}},
+		},
+		{
+			target:   Object{"a": []any{map[string]any{"b": "c"}}},
+			patch:    Object{"a": []any{float64(1)}},
+			expected: Object{"a": []any{float64(1)}},
+		},
+		{target: Object{"e": nil}, patch: Object{"a": float64(1)}, expected: Object{"e": nil, "a": float64(1)}},
+		{target: Object{"a": "foo"}, patch: Object{"a": map[string]any{"b": "c"}}, expected: Object{"a": map[string]any{"b": "c"}}},
+		{target: Object{}, patch: Object{"a": map[string]any{"bb": map[string]any{"ccc": nil}}}, expected: Object{"a": map[string]any{"bb": map[string]any{}}}},
+	}
+
+	for _, c := range cases {
+		merged := c.target.MergePatch(c.patch)
+
+		if !reflect.DeepEqual(merged, c.expected) {
+			t.Errorf("patching %v with %v returned %v, expected %v", c.target, c.patch, merged, c.expected)
+		}
+	}
+}
+
+func TestMergePatchKeepsTarget(t *testing.T) {
+	target := Object{"a": map[string]any{"b": "c"}, "d": "e"}
+
+	target.MergePatch(Object{"a": map[string]any{"b": nil}, "d": nil})
+
+	expected := Object{"a": map[string]any{"b": "c"}, "d": "e"}
+	if !reflect.DeepEqual(target, expected) {
+		t.Errorf("target changed to %v", target)
+	}
+}
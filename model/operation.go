@@ -0,0 +1,105 @@
+package model
+
+import "reflect"
+
+type OperationType string
+
+const (
+	SetOperation    OperationType = "set"
+	DeleteOperation OperationType = "delete"
+	CheckOperation  OperationType = "check"
+)
+
+type Operation struct {
+	Type      OperationType `json:"op"`
+	Bucket    string        `json:"bucket,omitempty"`
+	Key       string        `json:"key"`
+	Value     Object        `json:"value,omitempty"`
+	TTL       int64         `json:"ttl,omitempty"`
+	Condition *Condition    `json:"condition,omitempty"`
+}
+
+type Condition struct {
+	Exists *bool  `json:"exists,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Equals any    `json:"equals,omitempty"`
+}
+
+type OperationStatus string
+
+const (
+	OperationApplied OperationStatus = "ok"
+	OperationFailed  OperationStatus = "failed"
+	OperationAborted OperationStatus = "aborted"
+)
+
+type OperationResult struct {
+	Type    OperationType   `json:"op"`
+	Bucket  string          `json:"bucket,omitempty"`
+	Key     string          `json:"key"`
+	Status  OperationStatus `json:"status"`
+	Version int64           `json:"version,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+func NewOperationResults(operations []Operation) []OperationResult {
+	results := make([]OperationResult, len(operations))
+
+	for i, operation := range operations {
+		results[i] = OperationResult{
+			Type:   operation.Type,
+			Bucket: operation.Bucket,
+			Key:    operation.Key,
+			Status: OperationAborted,
+		}
+	}
+
+	return results
+}
+
+func AbortResults(results []OperationResult) []OperationResult {
+	for i := range results {
+		if results[i].Status == OperationApplied {
+			results[i].Status = OperationAborted
+			results[i].Version = 0
+		}
+	}
+
+	return results
+}
+
+// WithDefaults applies the default values of the schema to the value of set operations
+func (o Operation) WithDefaults(schema []Field) Operation {
+	if o.Type == SetOperation {
+		o.Value = o.Value.WithDefaults(schema)
+	}
+
+	return o
+}
+
+func (o Operation) Normalize(schema []Field) Operation {
+	o.Value = o.Value.Normalize(schema)
+
+	if o.Condition != nil {
+		condition := *o.Condition
+		if field, found := FieldByName(schema, condition.Field); found {
+			condition.Equals = field.Normalize(condition.Equals)
+		}
+
+		o.Condition = &condition
+	}
+
+	return o
+}
+
+func (c Condition) Check(obj Object) bool {
+	if c.Exists != nil {
+		return *c.Exists == (obj != nil)
+	}
+
+	if obj == nil {
+		return false
+	}
+
+	return reflect.DeepEqual(obj[c.Field], c.Equals)
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+type BucketMode string
+
+const (
+	SchemaMode   BucketMode = "schema"
+	DocumentMode BucketMode = "document"
+)
+
+// DocumentField holds the values of the fields not declared on the schema of document buckets
+const DocumentField = "_document"
+
+type BucketOptions struct {
+	DefaultTTL int64      `json:"default-ttl,omitempty"`
+	History    bool       `json:"history,omitempty"`
+	Indexes    []Index    `json:"indexes,omitempty"`
+	Mode       BucketMode `json:"mode,omitempty"`
+}
+
+type Index struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+	Unique bool     `json:"unique,omitempty"`
+}
+
+func (o BucketOptions) IsDocument() bool {
+	return o.Mode == DocumentMode
+}
+
+// WithDocument returns the schema with the document field, on document buckets,
+// so undeclared fields can be found by FieldByPath
+func (o BucketOptions) WithDocument(schema []Field) []Field {
+	if !o.IsDocument() {
+		return schema
+	}
+
+	return append(slices.Clip(schema), Field{Name: DocumentField, Type: ObjectDataType})
+}
+
+func (o BucketOptions) TTL() time.Duration {
+	return time.Duration(o.DefaultTTL) * time.Second
+}
+
+func (o BucketOptions) IndexesWith(field string) []Index {
+	indexes := make([]Index, 0)
+
+	for _, index := range o.Indexes {
+		if slices.Contains(index.Fields, field) {
+			indexes = append(indexes, index)
+		}
+	}
+
+	return indexes
+}
+
+// IsUnique returns true when the field has an unique index of its own
+func (o BucketOptions) IsUnique(field string) bool {
+	for _, index := range o.Indexes {
+		if index.Unique && len(index.Fields) == 1 && index.Fields[0] == field {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,28 @@
+package model
+
+import "slices"
+
+// Precondition holds the conditional headers, Versions are the versions listed on If-Match
+type Precondition struct {
+	Versions     []int64
+	MustExist    bool
+	MustNotExist bool
+}
+
+func (p Precondition) Check(version int64) bool {
+	exists := version > 0
+
+	if p.MustExist && !exists {
+		return false
+	}
+
+	if p.MustNotExist && exists {
+		return false
+	}
+
+	if len(p.Versions) > 0 && !slices.Contains(p.Versions, version) {
+		return false
+	}
+
+	return true
+}
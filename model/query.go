@@ -0,0 +1,181 @@
+package model
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	FieldsParameter = "fields"
+	SortParameter   = "sort"
+	LimitParameter  = "limit"
+	CursorParameter = "cursor"
+	SearchParameter = "q"
+)
+
+// RankField sorts full-text search results by relevance
+const RankField = "_rank"
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+var reservedParameters = []string{FieldsParameter, SortParameter, LimitParameter, CursorParameter, SearchParameter}
+
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+type Query struct {
+	Criteria Criteria
+	Filter   *Filter
+	Fields   []string
+	Sort     []SortField
+	Search   []string
+	Limit    int
+	After    []any
+}
+
+type Entry struct {
+	Key     string `json:"key"`
+	Version int64  `json:"version"`
+	Value   Object `json:"value"`
+}
+
+// CriteriaParameters returns the query parameters without the reserved ones
+func CriteriaParameters(parameters url.Values) url.Values {
+	return removeParameters(parameters, reservedParameters)
+}
+
+func ParseFields(parameters url.Values) []string {
+	return splitList(parameters[FieldsParameter])
+}
+
+// ParseSort reads a list like name,-age, a leading minus means descending order
+func ParseSort(parameters url.Values) []SortField {
+	return parseSortList(splitList(parameters[SortParameter]))
+}
+
+func parseSortList(items []string) []SortField {
+	sort := make([]SortField, 0)
+
+	for _, item := range items {
+		field := SortField{Field: item}
+
+		if strings.HasPrefix(item, "-") {
+			field.Field = item[1:]
+			field.Descending = true
+		}
+
+		sort = append(sort, field)
+	}
+
+	return sort
+}
+
+// SortOrder returns the requested sort, full-text searches are sorted by relevance by default
+func SortOrder(parameters url.Values) []SortField {
+	sort := ParseSort(parameters)
+	if len(sort) == 0 && parameters.Has(SearchParameter) {
+		sort = append(sort, SortField{Field: RankField})
+	}
+
+	return sort
+}
+
+func ParseSearch(parameters url.Values) []string {
+	return strings.Fields(strings.Join(parameters[SearchParameter], " "))
+}
+
+// Paginated returns true when the request asks for a page instead of the full result
+func Paginated(parameters url.Values) bool {
+	return parameters.Has(LimitParameter) || parameters.Has(CursorParameter)
+}
+
+func ParseLimit(parameters url.Values) (int, error) {
+	if !parameters.Has(LimitParameter) {
+		if parameters.Has(CursorParameter) {
+			return DefaultLimit, nil
+		}
+
+		return 0, nil
+	}
+
+	return strconv.Atoi(parameters.Get(LimitParameter))
+}
+
+func ParseCursor(parameters url.Values) (*Cursor, error) {
+	if !parameters.Has(CursorParameter) {
+		return nil, nil
+	}
+
+	cursor, err := DecodeCursor(parameters.Get(CursorParameter))
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+func NewQuery(parameters url.Values, schema []Field) (Query, error) {
+	criteria, err := Convert(CriteriaParameters(parameters), schema)
+	if err != nil {
+		return Query{}, err
+	}
+
+	limit, err := ParseLimit(parameters)
+	if err != nil {
+		return Query{}, err
+	}
+
+	cursor, err := ParseCursor(parameters)
+	if err != nil {
+		return Query{}, err
+	}
+
+	query := Query{
+		Criteria: criteria,
+		Fields:   ParseFields(parameters),
+		Sort:     SortOrder(parameters),
+		Search:   ParseSearch(parameters),
+		Limit:    limit,
+	}
+
+	if cursor != nil {
+		query.After = cursor.After
+	}
+
+	return query, nil
+}
+
+func splitList(values []string) []string {
+	items := make([]string, 0)
+
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if len(item) > 0 {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
+func removeParameters(parameters url.Values, names []string) url.Values {
+	output := make(url.Values)
+
+	for name, values := range parameters {
+		output[name] = values
+	}
+
+	for _, name := range names {
+		delete(output, name)
+	}
+
+	return output
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Revision struct {
+	Version   int64      `json:"version"`
+	ValidFrom time.Time  `json:"valid-from"`
+	ValidTo   *time.Time `json:"valid-to,omitempty"`
+	Value     Object     `json:"value"`
+}
@@ -1,8 +1,147 @@
 package model
 
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var documentPathRegExp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*(\.[a-zA-Z][a-zA-Z0-9_]*)*$`)
+
 type Field struct {
-	Name     string   `json:"field"`
-	Type     DataType `json:"type"`
-	Required bool     `json:"not-null"`
-	Indexed  bool     `json:"indexed"`
+	Name       string   `json:"field"`
+	Type       DataType `json:"type"`
+	Values     []string `json:"values,omitempty"`
+	Items      *Field   `json:"items,omitempty"`
+	Fields     []Field  `json:"fields,omitempty"`
+	Required   bool     `json:"not-null"`
+	Indexed    bool     `json:"indexed"`
+	Searchable bool     `json:"searchable"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	MinLength  *int     `json:"minLength,omitempty"`
+	MaxLength  *int     `json:"maxLength,omitempty"`
+	Pattern    string   `json:"pattern,omitempty"`
+	Default    any      `json:"default,omitempty"`
+}
+
+// ValidValue checks the value type, enum values must also be one of the field values
+// and the contents of objects and arrays must match their nested fields
+func (f Field) ValidValue(value any) bool {
+	if !f.Type.ValidValue(value) {
+		return false
+	}
+
+	switch f.Type {
+	case EnumDataType:
+		return slices.Contains(f.Values, value.(string))
+	case ObjectDataType:
+		obj := value.(map[string]any)
+		for name, nested := range obj {
+			field, found := FieldByName(f.Fields, name)
+			if !found || !field.ValidValue(nested) {
+				return false
+			}
+		}
+
+		for _, field := range f.Fields {
+			if _, found := obj[field.Name]; field.Required && !found {
+				return false
+			}
+		}
+	case ArrayDataType:
+		if f.Items == nil {
+			return false
+		}
+
+		for _, item := range value.([]any) {
+			if !f.Items.ValidValue(item) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Normalize converts a valid value to the representation used to store and compare values
+func (f Field) Normalize(value any) any {
+	switch f.Type {
+	case ObjectDataType:
+		if obj, ok := value.(map[string]any); ok {
+			return map[string]any(Object(obj).Normalize(f.Fields))
+		}
+	case ArrayDataType:
+		if list, ok := value.([]any); ok && f.Items != nil {
+			normalized := make([]any, 0, len(list))
+			for _, item := range list {
+				normalized = append(normalized, f.Items.Normalize(item))
+			}
+
+			return normalized
+		}
+	}
+
+	return f.Type.Normalize(value)
+}
+
+func FieldByName(schema []Field, name string) (Field, bool) {
+	for _, field := range schema {
+		if field.Name == name {
+			return field, true
+		}
+	}
+
+	return Field{}, false
+}
+
+// FieldByPath finds a field using a dotted path, e.g. address.city, with the names of object fields.
+// When the schema has the document field, undeclared paths are found as dynamic fields named after their path on the document.
+func FieldByPath(schema []Field, path string) (Field, bool) {
+	names := strings.Split(path, ".")
+
+	field, found := FieldByName(schema, names[0])
+	if !found {
+		if _, document := FieldByName(schema, DocumentField); document && documentPathRegExp.MatchString(path) {
+			return Field{Name: DocumentField + "." + path, Type: DynamicDataType}, true
+		}
+	}
+
+	for _, name := range names[1:] {
+		if !found || field.Type != ObjectDataType {
+			return Field{}, false
+		}
+
+		field, found = FieldByName(field.Fields, name)
+	}
+
+	return field, found
+}
+
+type SchemaChange struct {
+	Add   []Field       `json:"add"`
+	Drop  []string      `json:"drop"`
+	Alter []FieldChange `json:"alter"`
+}
+
+type FieldChange struct {
+	Name       string `json:"field"`
+	Required   *bool  `json:"not-null"`
+	Indexed    *bool  `json:"indexed"`
+	Searchable *bool  `json:"searchable"`
+}
+
+func (c SchemaChange) IsEmpty() bool {
+	return len(c.Add) == 0 && len(c.Drop) == 0 && len(c.Alter) == 0
+}
+
+func SearchableFields(schema []Field) []string {
+	names := make([]string, 0)
+	for _, field := range schema {
+		if field.Searchable {
+			names = append(names, field.Name)
+		}
+	}
+
+	return names
 }
@@ -0,0 +1,94 @@
+package relational
+
+import (
+	"context"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func readAggregates(ctx context.Context, db dbConn, bucket *bucket, aggregation model.Aggregation) ([]model.AggregateResult, error) {
+	groupSchema := selectFields(bucket.schema, aggregation.GroupBy)
+	aggregateSchema := selectFields(bucket.schema, aggregateFields(aggregation.Aggregates))
+	for i, aggregate := range aggregation.Aggregates {
+		// the average of integers isn't an integer
+		if aggregate.Function == model.AvgFunction {
+			aggregateSchema[i].Type = model.NumberDataType
+		}
+	}
+
+	query, values := buildAggregateQuery(bucket, aggregation)
+	stm, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer stm.Close()
+
+	rows, err := stm.QueryContext(ctx, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]model.AggregateResult, 0)
+
+	for rows.Next() {
+		var count int64
+		groupValues := valuesForScan(groupSchema)
+		aggregateValues := valuesForScan(aggregateSchema)
+
+		targets := groupValues
+		if aggregation.Count {
+			targets = append(targets, &count)
+		}
+
+		if err = rows.Scan(append(targets, aggregateValues...)...); err != nil {
+			return nil, err
+		}
+
+		var result model.AggregateResult
+
+		if len(groupSchema) > 0 {
+			result.Group = buildObject(groupSchema, groupValues)
+			for _, field := range groupSchema {
+				if _, found := result.Group[field.Name]; !found {
+					result.Group[field.Name] = nil
+				}
+			}
+		}
+
+		if aggregation.Count {
+			result.Count = &count
+		}
+
+		for i, aggregate := range aggregation.Aggregates {
+			value := buildObject(aggregateSchema[i:i+1], aggregateValues[i:i+1])
+			result.Set(aggregate, value[aggregate.Field])
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func aggregateFields(aggregates []model.Aggregate) []string {
+	names := make([]string, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		names = append(names, aggregate.Field)
+	}
+
+	return names
+}
+
+// selectFields returns the fields with the given names, on the order of the names
+func selectFields(schema []model.Field, names []string) []model.Field {
+	fields := make([]model.Field, 0, len(names))
+
+	for _, name := range names {
+		if position := fieldPosition(schema, name); position >= 0 {
+			fields = append(fields, schema[position])
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,141 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func alterTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field, options model.BucketOptions, change model.SchemaChange) ([]model.Field, error) {
+	newSchema := slices.Clone(schema)
+	rebuild := false
+
+	for _, name := range change.Drop {
+		pos := fieldPosition(newSchema, name)
+		if pos < 0 {
+			return nil, apperror.UnknownField.New(name)
+		}
+
+		if newSchema[pos].Indexed {
+			if err := dropIndex(ctx, tx, tableName, name); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := dropColumn(ctx, tx, tableName, name); err != nil {
+			return nil, err
+		}
+
+		if options.History {
+			if err := dropColumn(ctx, tx, historyTable(tableName), name); err != nil {
+				return nil, err
+			}
+		}
+
+		newSchema = slices.Delete(newSchema, pos, pos+1)
+	}
+
+	for _, field := range change.Add {
+		if fieldPosition(newSchema, field.Name) >= 0 {
+			return nil, apperror.FieldAlreadyExists.New(field.Name)
+		}
+
+		if field.Required {
+			count, err := countRows(ctx, tx, tableName, "")
+			if err != nil {
+				return nil, err
+			}
+
+			if count > 0 {
+				return nil, apperror.IncompatibleSchemaChange.New(field.Name)
+			}
+
+			rebuild = true
+		}
+
+		if err := addColumn(ctx, tx, tableName, nullable(field)); err != nil {
+			return nil, err
+		}
+
+		if options.History {
+			if err := addColumn(ctx, tx, historyTable(tableName), nullable(field)); err != nil {
+				return nil, err
+			}
+		}
+
+		if field.Indexed {
+			if err := createIndex(ctx, tx, tableName, field.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		newSchema = append(newSchema, field)
+	}
+
+	for _, fieldChange := range change.Alter {
+		pos := fieldPosition(newSchema, fieldChange.Name)
+		if pos < 0 {
+			return nil, apperror.UnknownField.New(fieldChange.Name)
+		}
+
+		field := &newSchema[pos]
+
+		if fieldChange.Required != nil && *fieldChange.Required != field.Required {
+			if *fieldChange.Required {
+				count, err := countRows(ctx, tx, tableName, field.Name+" is null")
+				if err != nil {
+					return nil, err
+				}
+
+				if count > 0 {
+					return nil, apperror.IncompatibleSchemaChange.New(field.Name)
+				}
+			}
+
+			field.Required = *fieldChange.Required
+			rebuild = true
+		}
+
+		if fieldChange.Indexed != nil && *fieldChange.Indexed != field.Indexed {
+			var err error
+			if *fieldChange.Indexed {
+				err = createIndex(ctx, tx, tableName, field.Name)
+			} else {
+				err = dropIndex(ctx, tx, tableName, field.Name)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			field.Indexed = *fieldChange.Indexed
+		}
+
+		if fieldChange.Searchable != nil {
+			field.Searchable = *fieldChange.Searchable
+		}
+	}
+
+	if rebuild {
+		if err := rebuildTable(ctx, tx, tableName, options.WithDocument(newSchema), options.Indexes); err != nil {
+			return nil, err
+		}
+	}
+
+	if searchableChanged(schema, newSchema) {
+		if err := rebuildSearchTable(ctx, tx, tableName, newSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	return newSchema, nil
+}
+
+func fieldPosition(schema []model.Field, name string) int {
+	return slices.IndexFunc(schema, func(field model.Field) bool {
+		return field.Name == name
+	})
+}
@@ -0,0 +1,100 @@
+package relational
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestAlterBucket(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType, Required: true}}
+	if _, err := repo.NewBucket(ctx, "people", schema, model.BucketOptions{}); err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	change := model.SchemaChange{
+		Add: []model.Field{{Name: "age", Type: model.IntegerDataType, Indexed: true}},
+	}
+
+	bucket, err := repo.AlterBucket(ctx, "people", change)
+	if err != nil {
+		t.Fatalf("adding field: %v", err)
+	}
+
+	if len(bucket.Schema()) != 2 || bucket.Schema()[1].Name != "age" {
+		t.Fatalf("unexpected schema %v", bucket.Schema())
+	}
+
+	if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Joe", "age": int64(30)}, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	bucket, err = repo.AlterBucket(ctx, "people", model.SchemaChange{Drop: []string{"age"}})
+	if err != nil {
+		t.Fatalf("dropping field: %v", err)
+	}
+
+	value, _, err := bucket.Read(ctx, "k1")
+	if err != nil {
+		t.Fatalf("reading value: %v", err)
+	}
+
+	if _, found := value["age"]; found || value["name"] != "Joe" {
+		t.Errorf("unexpected value %v after dropping age", value)
+	}
+}
+
+// a field can't become not-null while existing values don't have it, the schema must stay unchanged
+func TestIncompatibleSchemaChange(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{
+		{Name: "name", Type: model.StringDataType},
+		{Name: "email", Type: model.StringDataType},
+	}
+
+	bucket, err := repo.NewBucket(ctx, "people", schema, model.BucketOptions{})
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Joe"}, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	required := true
+	changes := map[string]model.SchemaChange{
+		"add not-null field":      {Add: []model.Field{{Name: "age", Type: model.IntegerDataType, Required: true}}},
+		"alter field to not-null": {Alter: []model.FieldChange{{Name: "email", Required: &required}}},
+	}
+
+	for name, change := range changes {
+		if _, err = repo.AlterBucket(ctx, "people", change); !isErrorType(err, apperror.IncompatibleSchemaChange) {
+			t.Errorf("%v: expected an incompatible schema change, got %v", name, err)
+		}
+	}
+
+	bucket, err = repo.GetBucket(ctx, "people")
+	if err != nil {
+		t.Fatalf("reading bucket: %v", err)
+	}
+
+	if len(bucket.Schema()) != 2 || bucket.Schema()[1].Required {
+		t.Errorf("schema changed to %v", bucket.Schema())
+	}
+
+	// once every value has the field, it can become not-null
+	if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Joe", "email": "joe@example.com"}, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	if _, err = repo.AlterBucket(ctx, "people", changes["alter field to not-null"]); err != nil {
+		t.Errorf("altering field to not-null: %v", err)
+	}
+}
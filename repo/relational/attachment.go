@@ -0,0 +1,196 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+// attachments are stored in parts, so they are streamed without holding the whole content in memory
+const attachmentPartSize = 64 << 10
+
+func attachmentsTable(tableName string) string {
+	return "_attachments_" + tableName
+}
+
+func createAttachmentsTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "create table " + attachmentsTable(tableName) + ` (
+				key varchar(50) not null,
+				name varchar(100) not null,
+				part integer not null,
+				upload integer not null,
+				content_type text not null,
+				content blob not null,
+				primary key (key, name, part)
+			)`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func dropAttachmentsTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "drop table if exists " + attachmentsTable(tableName)
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func storeAttachment(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, attachment model.Attachment) error {
+	version, err := keyVersion(ctx, tx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return apperror.KeyNotFound.New(key, bucket.name)
+	}
+
+	// each upload is numbered, so a reader notices when the parts it reads are replaced
+	var upload int64
+
+	query := "select coalesce(max(upload), 0) + 1 from " + attachmentsTable(bucket.name) + " where key = ? and name = ?"
+
+	err = tx.QueryRowContext(ctx, query, key, attachment.Name).Scan(&upload)
+	if err != nil {
+		return err
+	}
+
+	query = "delete from " + attachmentsTable(bucket.name) + " where key = ? and name = ?"
+
+	_, err = tx.ExecContext(ctx, query, key, attachment.Name)
+	if err != nil {
+		return err
+	}
+
+	query = "insert into " + attachmentsTable(bucket.name) + " (key, name, part, upload, content_type, content) values (?, ?, ?, ?, ?, ?)"
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	buffer := make([]byte, attachmentPartSize)
+
+	for part := 0; ; part++ {
+		size, err := io.ReadFull(attachment.Content, buffer)
+		last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !last {
+			return err
+		}
+
+		// an empty attachment is stored as an empty part
+		if size > 0 || part == 0 {
+			_, err = stmt.ExecContext(ctx, key, attachment.Name, part, upload, attachment.ContentType, buffer[:size])
+			if err != nil {
+				return err
+			}
+		}
+
+		if last {
+			return nil
+		}
+	}
+}
+
+// readAttachment returns the attachment with a reader of its parts, the parts are read using ctx
+func readAttachment(ctx context.Context, db dbConn, bucket *bucket, key string, name string) (*model.Attachment, error) {
+	query := "select a.content_type, a.upload, (select count(*) from " + attachmentsTable(bucket.name) + " p" +
+		" where p.key = a.key and p.name = a.name) from " + attachmentsTable(bucket.name) + " a" +
+		" join " + bucket.name + " b on b.key = a.key" +
+		" where a.key = ? and a.name = ? and a.part = 0 and " + notExpired()
+
+	attachment := model.Attachment{Name: name}
+	reader := attachmentReader{
+		ctx:    ctx,
+		db:     db,
+		bucket: bucket,
+		key:    key,
+		name:   name,
+	}
+
+	err := db.QueryRowContext(ctx, query, key, name, now()).Scan(&attachment.ContentType, &reader.upload, &reader.parts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	attachment.Content = &reader
+	return &attachment, nil
+}
+
+// attachmentReader reads one part at a time, it fails if the attachment is replaced or deleted while read
+type attachmentReader struct {
+	ctx     context.Context
+	db      dbConn
+	bucket  *bucket
+	key     string
+	name    string
+	upload  int64
+	parts   int
+	part    int
+	content []byte
+}
+
+func (r *attachmentReader) Read(p []byte) (int, error) {
+	for len(r.content) == 0 {
+		if r.part == r.parts {
+			return 0, io.EOF
+		}
+
+		query := "select content from " + attachmentsTable(r.bucket.name) + " where key = ? and name = ? and part = ? and upload = ?"
+
+		err := r.db.QueryRowContext(r.ctx, query, r.key, r.name, r.part, r.upload).Scan(&r.content)
+		if err == sql.ErrNoRows {
+			return 0, errors.New("attachment " + r.name + " changed while read")
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		r.part++
+	}
+
+	size := copy(p, r.content)
+	r.content = r.content[size:]
+
+	return size, nil
+}
+
+func deleteAttachment(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, name string) (bool, error) {
+	version, err := keyVersion(ctx, tx, bucket, key)
+	if err != nil {
+		return false, err
+	}
+
+	if version == 0 {
+		return false, apperror.KeyNotFound.New(key, bucket.name)
+	}
+
+	query := "delete from " + attachmentsTable(bucket.name) + " where key = ? and name = ?"
+
+	result, err := tx.ExecContext(ctx, query, key, name)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	return count > 0, err
+}
+
+// deleteAttachments removes the attachments of the keys selected by where, before the keys are deleted
+func deleteAttachments(ctx context.Context, tx *sql.Tx, bucket *bucket, where string, args ...any) error {
+	query := "delete from " + attachmentsTable(bucket.name) +
+		" where key in (select key from " + bucket.name + " where " + where + ")"
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
@@ -3,14 +3,17 @@ package relational
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jjmrocha/oblivion/model"
 )
 
 type bucket struct {
-	repo   *sqlRepo
-	name   string
-	schema []model.Field
+	repo    *sqlRepo
+	tx      *sql.Tx
+	name    string
+	schema  []model.Field
+	options model.BucketOptions
 }
 
 func (b *bucket) Name() string {
@@ -21,79 +24,183 @@ func (b *bucket) Schema() []model.Field {
 	return b.schema
 }
 
-func (b *bucket) Store(ctx context.Context, key string, value model.Object) error {
-	exists, err := keyExists(ctx, b.repo.db, b, key)
-	if err != nil {
+func (b *bucket) Options() model.BucketOptions {
+	return b.options
+}
+
+// columns are the fields stored on the table, including the document field of document buckets
+func (b *bucket) columns() []model.Field {
+	return b.options.WithDocument(b.schema)
+}
+
+func (b *bucket) Store(ctx context.Context, key string, value model.Object, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	var version int64
+
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		version, err = storeValue(ctx, tx, b, key, value, precondition, ttl)
 		return err
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	if exists {
-		return updateValue(ctx, b.repo.db, b, key, value)
+	return version, nil
+}
+
+func (b *bucket) Update(ctx context.Context, key string, value model.Object, changed []string, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	var version int64
+
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		version, err = patchValue(ctx, tx, b, key, value, changed, precondition, ttl)
+		return err
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return insertValue(ctx, b.repo.db, b, key, value)
+	return version, nil
+}
+
+func (b *bucket) Read(ctx context.Context, key string) (model.Object, int64, error) {
+	return readValue(ctx, b.conn(), b, key)
+}
+
+func (b *bucket) ReadAt(ctx context.Context, key string, at time.Time) (model.Object, int64, error) {
+	return readValueAt(ctx, b.conn(), b, key, at)
 }
 
-func (b *bucket) Read(ctx context.Context, key string) (model.Object, error) {
-	query := buildFindByKeySql(b)
-	stm, err := b.repo.db.PrepareContext(ctx, query)
+func (b *bucket) History(ctx context.Context, key string) ([]model.Revision, error) {
+	return readHistory(ctx, b.conn(), b, key)
+}
+
+func (b *bucket) Delete(ctx context.Context, key string, precondition model.Precondition) error {
+	return b.inTx(ctx, func(tx *sql.Tx) error {
+		return deleteValue(ctx, tx, b, key, precondition)
+	})
+}
+
+func (b *bucket) StoreAttachment(ctx context.Context, key string, attachment model.Attachment) error {
+	return b.inTx(ctx, func(tx *sql.Tx) error {
+		return storeAttachment(ctx, tx, b, key, attachment)
+	})
+}
+
+func (b *bucket) ReadAttachment(ctx context.Context, key string, name string) (*model.Attachment, error) {
+	return readAttachment(ctx, b.conn(), b, key, name)
+}
+
+func (b *bucket) DeleteAttachment(ctx context.Context, key string, name string) (bool, error) {
+	var deleted bool
+
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		deleted, err = deleteAttachment(ctx, tx, b, key, name)
+		return err
+	})
+
+	return deleted, err
+}
+
+func (b *bucket) Keys(ctx context.Context, query model.Query) (model.Page[string], error) {
+	page := model.Page[string]{Items: make([]string, 0)}
+
+	newHolders := func() []any {
+		return nil
+	}
+
+	collect := func(key string, _ []any) {
+		page.Items = append(page.Items, key)
+	}
+
+	next, err := searchRows(ctx, b.conn(), b, nil, query, newHolders, collect)
 	if err != nil {
-		return nil, err
+		return page, err
 	}
 
-	defer stm.Close()
+	page.Next = next
+
+	return page, nil
+}
 
-	row := stm.QueryRowContext(ctx, key)
+func (b *bucket) Search(ctx context.Context, query model.Query) (model.Page[model.Entry], error) {
+	page := model.Page[model.Entry]{Items: make([]model.Entry, 0)}
+	schema := projection(b.columns(), query.Fields)
+	columns := append([]string{versionColumn}, fieldNames(schema)...)
 
-	values := valuesForScan(b.schema)
-	err = row.Scan(values...)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+	newHolders := func() []any {
+		var version int64
+		return append([]any{&version}, valuesForScan(schema)...)
+	}
+
+	collect := func(key string, holders []any) {
+		entry := model.Entry{
+			Key:     key,
+			Version: *holders[0].(*int64),
+			Value:   buildObject(schema, holders[1:]),
 		}
 
-		return nil, err
+		page.Items = append(page.Items, entry)
 	}
 
-	obj := buildObject(b.schema, values)
-	return obj, nil
+	next, err := searchRows(ctx, b.conn(), b, columns, query, newHolders, collect)
+	if err != nil {
+		return page, err
+	}
+
+	page.Next = next
+
+	return page, nil
 }
 
-func (b *bucket) Delete(ctx context.Context, key string) error {
-	query := "delete from " + b.name + " where key = ?"
-	stm, err := b.repo.db.PrepareContext(ctx, query)
-	if err != nil {
+func (b *bucket) Aggregate(ctx context.Context, aggregation model.Aggregation) ([]model.AggregateResult, error) {
+	return readAggregates(ctx, b.conn(), b, aggregation)
+}
+
+func (b *bucket) Apply(ctx context.Context, operation model.Operation) (int64, error) {
+	var version int64
+
+	err := b.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		version, err = applyOperation(ctx, tx, b, operation)
 		return err
+	})
+
+	return version, err
+}
+
+func (b *bucket) Changes(ctx context.Context, since int64, limit int) ([]model.Change, error) {
+	return readChanges(ctx, b.conn(), b, since, limit)
+}
+
+func (b *bucket) LastChange(ctx context.Context) (int64, error) {
+	return lastChange(ctx, b.conn(), b)
+}
+
+func (b *bucket) conn() dbConn {
+	if b.tx != nil {
+		return b.tx
 	}
 
-	_, err = stm.ExecContext(ctx, key)
-	return err
+	return b.repo.db
 }
 
-func (b *bucket) Keys(ctx context.Context, criteria model.Criteria) ([]string, error) {
-	query, values := buildSearchQuery(b, criteria)
-	stm, err := b.repo.db.PrepareContext(ctx, query)
-	if err != nil {
-		return nil, err
+func (b *bucket) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if b.tx != nil {
+		return fn(b.tx)
 	}
-	defer stm.Close()
 
-	rows, err := stm.QueryContext(ctx, values...)
+	tx, err := b.repo.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	keyList := make([]string, 0)
-	var key string
-
-	for rows.Next() {
-		if err = rows.Scan(&key); err != nil {
-			return nil, err
-		}
-
-		keyList = append(keyList, key)
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	return keyList, nil
+	return tx.Commit()
 }
@@ -10,7 +10,8 @@ import (
 func createCatalogIfNotExist(ctx context.Context, db *sql.DB) error {
 	query := `create table if not exists oblivion (
 				bucket_name varchar(30) primary key, 
-				schema text not null
+				schema text not null,
+				options text not null
 			)`
 
 	_, err := db.ExecContext(ctx, query)
@@ -18,8 +19,8 @@ func createCatalogIfNotExist(ctx context.Context, db *sql.DB) error {
 	return err
 }
 
-func addBucketToCatalog(ctx context.Context, tx *sql.Tx, bucket string, schema []model.Field) error {
-	stm, err := tx.PrepareContext(ctx, "insert into oblivion (bucket_name, schema) values (?, ?)")
+func addBucketToCatalog(ctx context.Context, tx *sql.Tx, bucket string, schema []model.Field, options model.BucketOptions) error {
+	stm, err := tx.PrepareContext(ctx, "insert into oblivion (bucket_name, schema, options) values (?, ?, ?)")
 	if err != nil {
 		return err
 	}
@@ -30,7 +31,28 @@ func addBucketToCatalog(ctx context.Context, tx *sql.Tx, bucket string, schema [
 		return err
 	}
 
-	_, err = stm.ExecContext(ctx, bucket, string(data))
+	optionData, err := marshalOptions(options)
+	if err != nil {
+		return err
+	}
+
+	_, err = stm.ExecContext(ctx, bucket, string(data), string(optionData))
+	return err
+}
+
+func updateBucketOnCatalog(ctx context.Context, tx *sql.Tx, bucket string, schema []model.Field) error {
+	stm, err := tx.PrepareContext(ctx, "update oblivion set schema = ? where bucket_name = ?")
+	if err != nil {
+		return err
+	}
+	defer stm.Close()
+
+	data, err := marshalSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	_, err = stm.ExecContext(ctx, string(data), bucket)
 	return err
 }
 
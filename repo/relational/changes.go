@@ -0,0 +1,109 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func changesTable(tableName string) string {
+	return "_changes_" + tableName
+}
+
+func createChangesTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "create table " + changesTable(tableName) + ` (
+				seq integer primary key autoincrement,
+				key varchar(50) not null,
+				op varchar(10) not null,
+				version integer not null,
+				value text,
+				changed_at integer not null
+			)`
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func dropChangesTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "drop table if exists " + changesTable(tableName)
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func recordChange(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, op model.OperationType, version int64, value model.Object) error {
+	var data any
+
+	if value != nil {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		data = string(encoded)
+	}
+
+	query := "insert into " + changesTable(bucket.name) + " (key, op, version, value, changed_at) values (?, ?, ?, ?, ?)"
+
+	_, err := tx.ExecContext(ctx, query, key, op, version, data, now())
+	return err
+}
+
+func recordDeletes(ctx context.Context, tx *sql.Tx, bucket *bucket, where string, args ...any) error {
+	query := "insert into " + changesTable(bucket.name) + " (key, op, version, value, changed_at)" +
+		" select key, ?, " + versionColumn + ", null, ? from " + bucket.name + " where " + where
+
+	_, err := tx.ExecContext(ctx, query, append([]any{model.DeleteOperation, now()}, args...)...)
+	return err
+}
+
+func readChanges(ctx context.Context, db dbConn, bucket *bucket, since int64, limit int) ([]model.Change, error) {
+	query := "select seq, op, key, version, value, changed_at from " + changesTable(bucket.name) + " where seq > ? order by seq limit ?"
+
+	rows, err := db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]model.Change, 0)
+
+	for rows.Next() {
+		var change model.Change
+		var value sql.NullString
+		var changedAt int64
+
+		err = rows.Scan(&change.Sequence, &change.Type, &change.Key, &change.Version, &value, &changedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if value.Valid {
+			decoder := json.NewDecoder(strings.NewReader(value.String))
+			decoder.UseNumber()
+
+			if err = decoder.Decode(&change.Value); err != nil {
+				return nil, err
+			}
+
+			change.Value = change.Value.Normalize(bucket.schema)
+		}
+
+		change.At = time.UnixMilli(changedAt).UTC()
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+func lastChange(ctx context.Context, db dbConn, bucket *bucket) (int64, error) {
+	query := "select coalesce(max(seq), 0) from " + changesTable(bucket.name)
+
+	var seq int64
+	err := db.QueryRowContext(ctx, query).Scan(&seq)
+	return seq, err
+}
@@ -0,0 +1,71 @@
+package relational
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	_ExpiryInterval  = 30 * time.Second
+	_ExpiryBatchSize = 500
+)
+
+func (r *sqlRepo) expireKeys() {
+	defer r.reaper.Done()
+
+	ticker := time.NewTicker(_ExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.removeExpiredKeys()
+		}
+	}
+}
+
+func (r *sqlRepo) removeExpiredKeys() {
+	ctx, cancel := context.WithTimeout(context.Background(), _ExpiryInterval)
+	defer cancel()
+
+	buckets, err := bucketList(ctx, r.db)
+	if err != nil {
+		log.Printf("Error listing buckets for key expiration: %v\n", err)
+		return
+	}
+
+	for _, name := range buckets {
+		schema, options, err := readDefinition(ctx, r.db, name)
+		if err != nil {
+			continue
+		}
+
+		bucket := bucket{
+			repo:    r,
+			name:    name,
+			schema:  schema,
+			options: options,
+		}
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			default:
+			}
+
+			count, err := deleteExpiredKeys(ctx, r.db, &bucket, _ExpiryBatchSize)
+			if err != nil {
+				log.Printf("Error removing expired keys from bucket %v: %v\n", name, err)
+				break
+			}
+
+			if count < _ExpiryBatchSize {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package relational
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestKeyExpiry(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType}}
+
+	bucket, err := repo.NewBucket(ctx, "sessions", schema, model.BucketOptions{})
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Joe"}, model.Precondition{}, 50*time.Millisecond); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k2", model.Object{"name": "Ann"}, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	if value, _, err := bucket.Read(ctx, "k1"); err != nil || value == nil {
+		t.Fatalf("key expired before its ttl, value %v, error %v", value, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	// expired keys aren't visible before the reaper removes them
+	if value, _, err := bucket.Read(ctx, "k1"); err != nil || value != nil {
+		t.Errorf("expired key was read, value %v, error %v", value, err)
+	}
+
+	page, err := bucket.Keys(ctx, model.Query{})
+	if err != nil {
+		t.Fatalf("listing keys: %v", err)
+	}
+
+	if len(page.Items) != 1 || page.Items[0] != "k2" {
+		t.Errorf("expected only k2, got %v", page.Items)
+	}
+
+	// an expired key is created again, starting a new version
+	version, err := bucket.Store(ctx, "k1", model.Object{"name": "Joe"}, model.Precondition{MustNotExist: true}, 0)
+	if err != nil || version != 1 {
+		t.Errorf("creating an expired key again: version %v, error %v", version, err)
+	}
+}
+
+func TestExpiredKeysReaper(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType}}
+
+	bucket, err := repo.NewBucket(ctx, "sessions", schema, model.BucketOptions{DefaultTTL: 1})
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	for _, key := range []string{"k1", "k2", "k3"} {
+		if _, err = bucket.Store(ctx, key, model.Object{"name": key}, model.Precondition{}, 0); err != nil {
+			t.Fatalf("storing %v: %v", key, err)
+		}
+	}
+
+	// the default ttl of the bucket is replaced by the ttl of the value
+	if _, err = bucket.Store(ctx, "k4", model.Object{"name": "k4"}, model.Precondition{}, time.Hour); err != nil {
+		t.Fatalf("storing k4: %v", err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	sqlRepo := repo.(*sqlRepo)
+	sqlRepo.removeExpiredKeys()
+
+	var count int
+	if err = sqlRepo.db.QueryRowContext(ctx, "select count(*) from sessions").Scan(&count); err != nil {
+		t.Fatalf("counting keys: %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("expected 1 key after removing the expired keys, found %v", count)
+	}
+}
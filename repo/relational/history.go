@@ -0,0 +1,128 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+const (
+	validFromColumn = "_valid_from"
+	validToColumn   = "_valid_to"
+)
+
+func historyTable(tableName string) string {
+	return "_history_" + tableName
+}
+
+func createHistoryTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field) error {
+	history := historyTable(tableName)
+
+	query := "create table " + history + " (key varchar(50) not null, " + versionColumn + " integer not null, " + validFromColumn + " integer not null, " + validToColumn + " integer not null"
+	for _, field := range schema {
+		query += " , " + columnDefinition(nullable(field))
+	}
+	query += ")"
+
+	_, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	query = "create index " + indexName(history, "key") + " on " + history + " (key, " + validFromColumn + ")"
+
+	_, err = tx.ExecContext(ctx, query)
+	return err
+}
+
+func dropHistoryTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "drop table if exists " + historyTable(tableName)
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func nullable(field model.Field) model.Field {
+	field.Required = false
+	return field
+}
+
+// validTo is the column or parameter marking the end of the archived versions
+func archiveValues(ctx context.Context, tx *sql.Tx, bucket *bucket, validTo string, where string, args ...any) error {
+	if !bucket.options.History {
+		return nil
+	}
+
+	fields := fieldNames(bucket.columns())
+	target := append([]string{"key", versionColumn, validFromColumn, validToColumn}, fields...)
+	source := append([]string{"key", versionColumn, modifiedColumn, validTo}, fields...)
+
+	query := "insert into " + historyTable(bucket.name) + " (" + strings.Join(target, ", ") + ")" +
+		" select " + strings.Join(source, ", ") + " from " + bucket.name + " where " + where
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func readValueAt(ctx context.Context, db dbConn, bucket *bucket, key string, at time.Time) (model.Object, int64, error) {
+	columnList := strings.Join(fieldNames(bucket.columns()), ", ")
+	timestamp := at.UnixMilli()
+
+	// the current value must have been alive at the timestamp, not only now, and the most recent version is read
+	query := "select " + versionColumn + ", " + columnList + " from (" +
+		"select " + versionColumn + ", " + modifiedColumn + " as " + validFromColumn + ", " + columnList + " from " + bucket.name +
+		" where key = ? and " + modifiedColumn + " <= ? and (" + expiresColumn + " is null or " + expiresColumn + " > ?)" +
+		" union all select " + versionColumn + ", " + validFromColumn + ", " + columnList + " from " + historyTable(bucket.name) +
+		" where key = ? and " + validFromColumn + " <= ? and " + validToColumn + " > ?" +
+		") order by " + validFromColumn + " desc limit 1"
+
+	row := db.QueryRowContext(ctx, query, key, timestamp, timestamp, key, timestamp, timestamp)
+	return scanValue(row, bucket.columns())
+}
+
+func readHistory(ctx context.Context, db dbConn, bucket *bucket, key string) ([]model.Revision, error) {
+	columnList := strings.Join(fieldNames(bucket.columns()), ", ")
+
+	query := "select " + versionColumn + ", " + validFromColumn + ", " + validToColumn + ", " + columnList + " from " + historyTable(bucket.name) +
+		" where key = ?" +
+		" union all select " + versionColumn + ", " + modifiedColumn + ", case when " + expiresColumn + " <= ? then " + expiresColumn + " end, " + columnList + " from " + bucket.name +
+		" where key = ?" +
+		" order by 2"
+
+	rows, err := db.QueryContext(ctx, query, key, now(), key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]model.Revision, 0)
+
+	for rows.Next() {
+		var version, validFrom int64
+		var validTo sql.NullInt64
+		values := valuesForScan(bucket.columns())
+
+		err = rows.Scan(append([]any{&version, &validFrom, &validTo}, values...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		revision := model.Revision{
+			Version:   version,
+			ValidFrom: time.UnixMilli(validFrom).UTC(),
+			Value:     buildObject(bucket.columns(), values),
+		}
+
+		if validTo.Valid {
+			end := time.UnixMilli(validTo.Int64).UTC()
+			revision.ValidTo = &end
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
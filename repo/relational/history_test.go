@@ -0,0 +1,120 @@
+package relational
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestReadAt(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType}}
+
+	bucket, err := repo.NewBucket(ctx, "people", schema, model.BucketOptions{History: true})
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	// timestamps have millisecond precision
+	tick := func() time.Time {
+		time.Sleep(5 * time.Millisecond)
+		defer time.Sleep(5 * time.Millisecond)
+		return time.Now()
+	}
+
+	before := tick()
+
+	for _, name := range []string{"Joe", "Ann"} {
+		if _, err = bucket.Store(ctx, "k1", model.Object{"name": name}, model.Precondition{}, 0); err != nil {
+			t.Fatalf("storing value: %v", err)
+		}
+	}
+
+	afterAnn := tick()
+
+	if err = bucket.Delete(ctx, "k1", model.Precondition{}); err != nil {
+		t.Fatalf("deleting value: %v", err)
+	}
+
+	afterDelete := tick()
+
+	cases := []struct {
+		at      time.Time
+		name    any
+		version int64
+	}{
+		{at: before},
+		{at: afterAnn, name: "Ann", version: 2},
+		{at: afterDelete},
+	}
+
+	for _, c := range cases {
+		value, version, err := bucket.ReadAt(ctx, "k1", c.at)
+		if err != nil {
+			t.Errorf("reading at %v: %v", c.at, err)
+			continue
+		}
+
+		if value["name"] != c.name || version != c.version {
+			t.Errorf("read %v with version %v at %v, expected %v with version %v", value, version, c.at, c.name, c.version)
+		}
+	}
+
+	revisions, err := bucket.History(ctx, "k1")
+	if err != nil {
+		t.Fatalf("reading history: %v", err)
+	}
+
+	if len(revisions) != 2 {
+		t.Fatalf("expected 2 revisions, got %v", revisions)
+	}
+
+	for _, revision := range revisions {
+		if revision.ValidTo == nil {
+			t.Errorf("revision %v of a deleted key is still valid", revision.Version)
+		}
+	}
+}
+
+// a value that expired is read until its expiration, also after the key is stored again
+func TestReadAtExpiredKey(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType}}
+
+	bucket, err := repo.NewBucket(ctx, "sessions", schema, model.BucketOptions{History: true})
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Joe"}, model.Precondition{}, 100*time.Millisecond); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	alive := time.Now()
+	time.Sleep(100 * time.Millisecond)
+
+	for _, stored := range []bool{false, true} {
+		if stored {
+			if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Ann"}, model.Precondition{}, 0); err != nil {
+				t.Fatalf("storing value: %v", err)
+			}
+		}
+
+		value, version, err := bucket.ReadAt(ctx, "k1", alive)
+		if err != nil || value["name"] != "Joe" || version != 1 {
+			t.Errorf("reading before the expiration (stored again: %v): value %v with version %v, error %v", stored, value, version, err)
+		}
+	}
+
+	value, version, err := bucket.ReadAt(ctx, "k1", time.Now())
+	if err != nil || value["name"] != "Ann" || version != 1 {
+		t.Errorf("reading the new value: value %v with version %v, error %v", value, version, err)
+	}
+}
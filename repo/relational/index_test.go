@@ -0,0 +1,62 @@
+package relational
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestUniqueViolation(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{
+		{Name: "country", Type: model.StringDataType},
+		{Name: "number", Type: model.StringDataType},
+		{Name: "email", Type: model.StringDataType},
+	}
+
+	// the fields of an index don't need to follow the order of the schema
+	options := model.BucketOptions{
+		Indexes: []model.Index{
+			{Name: "phone", Fields: []string{"number", "country"}, Unique: true},
+			{Name: "email_unique", Fields: []string{"email"}, Unique: true},
+		},
+	}
+
+	bucket, err := repo.NewBucket(ctx, "people", schema, options)
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	value := model.Object{"country": "PT", "number": "123", "email": "joe@example.com"}
+	if _, err = bucket.Store(ctx, "k1", value, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	cases := map[string]model.Object{
+		"phone":        {"country": "PT", "number": "123", "email": "ann@example.com"},
+		"email_unique": {"country": "ES", "number": "123", "email": "joe@example.com"},
+	}
+
+	for index, value := range cases {
+		_, err = bucket.Store(ctx, "k2", value, model.Precondition{}, 0)
+		if !isErrorType(err, apperror.UniqueViolation) {
+			t.Errorf("expected a violation of %v, got %v", index, err)
+			continue
+		}
+
+		if apperror.UniqueViolation.StatusCode() != http.StatusConflict || !strings.Contains(err.Error(), "index "+index+" ") {
+			t.Errorf("expected a conflict naming %v, got %v", index, err)
+		}
+	}
+
+	// the key with the value can store it again
+	if _, err = bucket.Store(ctx, "k1", value, model.Precondition{}, 0); err != nil {
+		t.Errorf("storing the same value on the same key: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+)
+
+// migrateCatalog adds to the tables created by previous versions the columns they are missing,
+// it only changes what is missing, so it runs on every start
+func migrateCatalog(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = migrateTables(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func migrateTables(ctx context.Context, tx *sql.Tx) error {
+	_, err := addMissingColumn(ctx, tx, "oblivion", "options", "text not null default '{}'")
+	if err != nil {
+		return err
+	}
+
+	buckets, err := catalogBuckets(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, bucket := range buckets {
+		if err = migrateBucketTable(ctx, tx, bucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func migrateBucketTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	_, err := addMissingColumn(ctx, tx, tableName, versionColumn, "integer not null default 1")
+	if err != nil {
+		return err
+	}
+
+	// values stored before the modification time was kept are considered as modified at the epoch
+	_, err = addMissingColumn(ctx, tx, tableName, modifiedColumn, "integer not null default 0")
+	if err != nil {
+		return err
+	}
+
+	added, err := addMissingColumn(ctx, tx, tableName, expiresColumn, "integer")
+	if err != nil {
+		return err
+	}
+
+	if added {
+		if err = createIndex(ctx, tx, tableName, expiresColumn); err != nil {
+			return err
+		}
+	}
+
+	exists, err := tableExists(ctx, tx, changesTable(tableName))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if err = createChangesTable(ctx, tx, tableName); err != nil {
+			return err
+		}
+	}
+
+	exists, err = tableExists(ctx, tx, attachmentsTable(tableName))
+	if err != nil || exists {
+		return err
+	}
+
+	return createAttachmentsTable(ctx, tx, tableName)
+}
+
+func catalogBuckets(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "select bucket_name from oblivion")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]string, 0)
+	var bucket string
+
+	for rows.Next() {
+		if err = rows.Scan(&bucket); err != nil {
+			return nil, err
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// addMissingColumn returns true when the column didn't exist and was added
+func addMissingColumn(ctx context.Context, tx *sql.Tx, tableName string, column string, definition string) (bool, error) {
+	columns, err := tableColumns(ctx, tx, tableName)
+	if err != nil {
+		return false, err
+	}
+
+	if slices.Contains(columns, column) {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, "alter table "+tableName+" add column "+column+" "+definition)
+	return err == nil, err
+}
+
+func tableExists(ctx context.Context, tx *sql.Tx, tableName string) (bool, error) {
+	columns, err := tableColumns(ctx, tx, tableName)
+	return len(columns) > 0, err
+}
+
+func tableColumns(ctx context.Context, tx *sql.Tx, tableName string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, "select name from pragma_table_info(?)", tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	var column string
+
+	for rows.Next() {
+		if err = rows.Scan(&column); err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
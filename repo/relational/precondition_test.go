@@ -0,0 +1,62 @@
+package relational
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func TestPreconditions(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{{Name: "name", Type: model.StringDataType}}
+
+	bucket, err := repo.NewBucket(ctx, "people", schema, model.BucketOptions{})
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	value := model.Object{"name": "Joe"}
+
+	if _, err = bucket.Store(ctx, "k1", value, model.Precondition{MustExist: true}, 0); !isErrorType(err, apperror.PreconditionFailed) {
+		t.Errorf("If-Match * on a missing key: expected a failed precondition, got %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", value, model.Precondition{MustNotExist: true}, 0); err != nil {
+		t.Fatalf("If-None-Match * on a missing key: %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", value, model.Precondition{MustNotExist: true}, 0); !isErrorType(err, apperror.PreconditionFailed) {
+		t.Errorf("If-None-Match * on an existing key: expected a failed precondition, got %v", err)
+	}
+
+	version, err := bucket.Store(ctx, "k1", value, model.Precondition{Versions: []int64{1}}, 0)
+	if err != nil || version != 2 {
+		t.Fatalf("If-Match with the current version: version %v, error %v", version, err)
+	}
+
+	// a stale version must not overwrite the value
+	if _, err = bucket.Store(ctx, "k1", model.Object{"name": "Ann"}, model.Precondition{Versions: []int64{1}}, 0); !isErrorType(err, apperror.PreconditionFailed) {
+		t.Errorf("If-Match with a stale version: expected a failed precondition, got %v", err)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", value, model.Precondition{Versions: []int64{1, 2}}, 0); err != nil {
+		t.Errorf("If-Match with a list of versions: %v", err)
+	}
+
+	if err = bucket.Delete(ctx, "k1", model.Precondition{Versions: []int64{2}}); !isErrorType(err, apperror.PreconditionFailed) {
+		t.Errorf("deleting with a stale version: expected a failed precondition, got %v", err)
+	}
+
+	current, version, err := bucket.Read(ctx, "k1")
+	if err != nil || version != 3 || current["name"] != "Joe" {
+		t.Errorf("unexpected value %v with version %v, error %v", current, version, err)
+	}
+
+	if err = bucket.Delete(ctx, "k1", model.Precondition{Versions: []int64{3}}); err != nil {
+		t.Errorf("deleting with the current version: %v", err)
+	}
+}
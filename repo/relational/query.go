@@ -0,0 +1,329 @@
+package relational
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+func buildWhere(criteria model.Criteria) (string, []any) {
+	where := notExpired()
+	values := make([]any, 0, len(criteria)+1)
+	values = append(values, now())
+
+	for _, criterion := range criteria {
+		condition, conditionValues := buildCondition(criterion)
+		where += " and " + condition
+		values = append(values, conditionValues...)
+	}
+
+	return where, values
+}
+
+func buildSearchQuery(bucket *bucket, columns []string, query model.Query) (string, []any) {
+	source, values := searchSource(bucket, query.Search)
+	where, whereValues := buildWhere(query.Criteria)
+	values = append(values, whereValues...)
+
+	if query.Filter != nil {
+		condition, conditionValues := buildFilter(query.Filter)
+		where += " and " + condition
+		values = append(values, conditionValues...)
+	}
+
+	if query.After != nil {
+		condition, conditionValues := buildAfterCondition(query.Sort, query.After)
+		where += " and " + condition
+		values = append(values, conditionValues...)
+	}
+
+	sqlQuery := "select " + strings.Join(columns, ", ") + " from " + source + " where " + where + " order by " + buildOrderBy(query.Sort)
+
+	// one extra row tells if there is a next page
+	if query.Limit > 0 {
+		sqlQuery += " limit ?"
+		values = append(values, query.Limit+1)
+	}
+
+	return sqlQuery, values
+}
+
+// key is always the last sort column, so the order is stable
+func buildOrderBy(sort []model.SortField) string {
+	columns := make([]string, 0, len(sort)+1)
+
+	for _, field := range sortWithKey(sort) {
+		if field.Descending {
+			columns = append(columns, field.Field+" desc")
+		} else {
+			columns = append(columns, field.Field)
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// buildAfterCondition selects the rows sorted after the cursor position,
+// nulls are sorted first, so they are before any value on ascending order and after on descending order
+func buildAfterCondition(sort []model.SortField, after []any) (string, []any) {
+	sort = sortWithKey(sort)
+	alternatives := make([]string, 0, len(sort))
+	values := make([]any, 0)
+
+	for i, field := range sort {
+		conditions := make([]string, 0, i+1)
+		alternativeValues := make([]any, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, sort[j].Field+" is ?")
+			alternativeValues = append(alternativeValues, after[j])
+		}
+
+		switch {
+		case after[i] == nil && field.Descending:
+			continue
+		case after[i] == nil:
+			conditions = append(conditions, field.Field+" is not null")
+		case field.Descending:
+			conditions = append(conditions, "("+field.Field+" < ? or "+field.Field+" is null)")
+			alternativeValues = append(alternativeValues, after[i])
+		default:
+			conditions = append(conditions, field.Field+" > ?")
+			alternativeValues = append(alternativeValues, after[i])
+		}
+
+		alternatives = append(alternatives, "("+strings.Join(conditions, " and ")+")")
+		values = append(values, alternativeValues...)
+	}
+
+	return "(" + strings.Join(alternatives, " or ") + ")", values
+}
+
+func sortWithKey(sort []model.SortField) []model.SortField {
+	return append(slices.Clone(sort), model.SortField{Field: "key"})
+}
+
+func sortColumns(sort []model.SortField) []string {
+	columns := make([]string, 0, len(sort))
+	for _, field := range sort {
+		columns = append(columns, field.Field)
+	}
+
+	return columns
+}
+
+// searchRows runs the search query selecting the key, the sort columns and the given columns,
+// the given columns are scanned into newHolders() and passed to collect, returns the cursor for the next page
+func searchRows(ctx context.Context, db dbConn, bucket *bucket, columns []string, query model.Query, newHolders func() []any, collect func(key string, holders []any)) (string, error) {
+	selected := append([]string{"key"}, sortColumns(query.Sort)...)
+	selected = append(selected, columns...)
+
+	sqlQuery, values := buildSearchQuery(bucket, selected, query)
+	stm, err := db.PrepareContext(ctx, sqlQuery)
+	if err != nil {
+		return "", err
+	}
+	defer stm.Close()
+
+	rows, err := stm.QueryContext(ctx, values...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var position []any
+	count := 0
+
+	for rows.Next() {
+		if query.Limit > 0 && count == query.Limit {
+			return model.NewCursor(query.Sort, position).Encode(), nil
+		}
+
+		var key string
+		sortValues := make([]any, len(query.Sort))
+		holders := newHolders()
+
+		targets := []any{&key}
+		for i := range sortValues {
+			targets = append(targets, &sortValues[i])
+		}
+
+		if err = rows.Scan(append(targets, holders...)...); err != nil {
+			return "", err
+		}
+
+		collect(key, holders)
+		position = append(sortValues, key)
+		count++
+	}
+
+	return "", rows.Err()
+}
+
+func buildAggregateQuery(bucket *bucket, aggregation model.Aggregation) (string, []any) {
+	where, values := buildWhere(aggregation.Criteria)
+	columns := slices.Clone(aggregation.GroupBy)
+
+	if aggregation.Count {
+		columns = append(columns, "count(*)")
+	}
+
+	for _, aggregate := range aggregation.Aggregates {
+		columns = append(columns, string(aggregate.Function)+"("+aggregate.Field+")")
+	}
+
+	sqlQuery := "select " + strings.Join(columns, ", ") + " from " + bucket.name + " where " + where
+
+	if len(aggregation.GroupBy) > 0 {
+		groupBy := strings.Join(aggregation.GroupBy, ", ")
+		sqlQuery += " group by " + groupBy + " order by " + groupBy
+	}
+
+	return sqlQuery, values
+}
+
+func projection(schema []model.Field, fields []string) []model.Field {
+	if len(fields) == 0 {
+		return schema
+	}
+
+	projected := make([]model.Field, 0, len(fields))
+
+	for _, field := range schema {
+		if slices.Contains(fields, field.Name) {
+			projected = append(projected, field)
+		}
+	}
+
+	return projected
+}
+
+// not uses coalesce, so fields without value are selected when the negated condition is unknown
+func buildFilter(filter *model.Filter) (string, []any) {
+	switch {
+	case filter.And != nil:
+		return buildFilterList(filter.And, " and ")
+	case filter.Or != nil:
+		return buildFilterList(filter.Or, " or ")
+	case filter.Not != nil:
+		condition, values := buildFilter(filter.Not)
+		return "not coalesce(" + condition + ", false)", values
+	}
+
+	criterion := model.Criterion{
+		Field:    filter.Field,
+		Operator: filter.Comparison(),
+		Values:   filter.Values(),
+	}
+
+	return buildCondition(criterion)
+}
+
+func buildFilterList(filters []model.Filter, separator string) (string, []any) {
+	conditions := make([]string, 0, len(filters))
+	values := make([]any, 0)
+
+	for i := range filters {
+		condition, conditionValues := buildFilter(&filters[i])
+		conditions = append(conditions, condition)
+		values = append(values, conditionValues...)
+	}
+
+	return "(" + strings.Join(conditions, separator) + ")", values
+}
+
+func buildCondition(criterion model.Criterion) (string, []any) {
+	field := columnExpression(criterion.Field)
+
+	if criterion.Operator == model.InOperator {
+		params := strings.Repeat(", ?", len(criterion.Values))[2:]
+		return "(" + field + " in (" + params + "))", criterion.Values
+	}
+	conditions := make([]string, 0, len(criterion.Values))
+	values := make([]any, 0, len(criterion.Values))
+	separator := " and "
+
+	for _, value := range criterion.Values {
+		switch criterion.Operator {
+		case model.EqualOperator:
+			conditions = append(conditions, field+" = ?")
+			values = append(values, value)
+			separator = " or "
+		case model.NotEqualOperator:
+			conditions = append(conditions, field+" is not ?")
+			values = append(values, value)
+		case model.GreaterOperator:
+			conditions = append(conditions, field+" > ?")
+			values = append(values, value)
+		case model.GreaterOrEqualOperator:
+			conditions = append(conditions, field+" >= ?")
+			values = append(values, value)
+		case model.LessOperator:
+			conditions = append(conditions, field+" < ?")
+			values = append(values, value)
+		case model.LessOrEqualOperator:
+			conditions = append(conditions, field+" <= ?")
+			values = append(values, value)
+		case model.PrefixOperator:
+			conditions = append(conditions, field+" glob ?")
+			values = append(values, globPrefix(value.(string)))
+			separator = " or "
+		case model.ContainsOperator:
+			conditions = append(conditions, "exists (select 1 from json_each("+jsonArguments(criterion.Field)+") where value = ?)")
+			values = append(values, value)
+		case model.ExistsOperator:
+			if value.(bool) {
+				conditions = append(conditions, field+" is not null")
+			} else {
+				conditions = append(conditions, field+" is null")
+			}
+		case model.NullOperator:
+			if value.(bool) {
+				conditions = append(conditions, field+" is null")
+			} else {
+				conditions = append(conditions, field+" is not null")
+			}
+		}
+	}
+
+	return "(" + strings.Join(conditions, separator) + ")", values
+}
+
+// columnExpression returns the column of a field, dotted paths use json_extract to read the value of object fields
+func columnExpression(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	return "json_extract(" + jsonArguments(path) + ")"
+}
+
+// jsonArguments splits a dotted path into the column and the JSON path, e.g. address.city becomes address, '$.city'
+func jsonArguments(path string) string {
+	column, rest, found := strings.Cut(path, ".")
+	if !found {
+		return column
+	}
+
+	return column + ", '$." + rest + "'"
+}
+
+// glob is case sensitive (unlike like), special characters are escaped using character classes
+func globPrefix(prefix string) string {
+	var pattern strings.Builder
+
+	for _, r := range prefix {
+		switch r {
+		case '*', '?', '[':
+			pattern.WriteString("[" + string(r) + "]")
+		default:
+			pattern.WriteRune(r)
+		}
+	}
+
+	pattern.WriteString("*")
+
+	return pattern.String()
+}
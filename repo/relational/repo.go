@@ -3,7 +3,9 @@ package relational
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/jjmrocha/oblivion/apperror"
@@ -12,7 +14,10 @@ import (
 )
 
 type sqlRepo struct {
-	db *sql.DB
+	db       *sql.DB
+	fullText bool
+	stop     chan struct{}
+	reaper   sync.WaitGroup
 }
 
 func New(driver string, datasource string) repo.Repository {
@@ -29,14 +34,32 @@ func New(driver string, datasource string) repo.Repository {
 		log.Panicf("Error creating db catalog on %v using driver %v: %v", datasource, driver, err)
 	}
 
+	err = migrateCatalog(ctx, db)
+	if err != nil {
+		log.Panicf("Error migrating db catalog on %v using driver %v: %v", datasource, driver, err)
+	}
+
+	fullText, err := fullTextSupported(ctx, db)
+	if err != nil {
+		log.Panicf("Error checking full-text search support on %v using driver %v: %v", datasource, driver, err)
+	}
+
 	repo := sqlRepo{
-		db: db,
+		db:       db,
+		fullText: fullText,
+		stop:     make(chan struct{}),
 	}
 
+	repo.reaper.Add(1)
+	go repo.expireKeys()
+
 	return &repo
 }
 
 func (r *sqlRepo) Close() {
+	close(r.stop)
+	r.reaper.Wait()
+
 	if err := r.db.Close(); err != nil {
 		log.Printf("Error closing db: %v\n", err)
 	}
@@ -46,7 +69,7 @@ func (r *sqlRepo) BucketNames(ctx context.Context) ([]string, error) {
 	return bucketList(ctx, r.db)
 }
 
-func (r *sqlRepo) NewBucket(ctx context.Context, name string, schema []model.Field) (repo.Bucket, error) {
+func (r *sqlRepo) NewBucket(ctx context.Context, name string, schema []model.Field, options model.BucketOptions) (repo.Bucket, error) {
 	exists, err := bucketExists(ctx, r.db, name)
 	if err != nil {
 		return nil, err
@@ -56,33 +79,59 @@ func (r *sqlRepo) NewBucket(ctx context.Context, name string, schema []model.Fie
 		return nil, apperror.BucketAlreadyExits.New(name)
 	}
 
+	if !r.fullText && len(model.SearchableFields(schema)) > 0 {
+		return nil, apperror.SearchNotSupported.New()
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	err = addBucketToCatalog(ctx, tx, name, schema)
+	err = addBucketToCatalog(ctx, tx, name, schema, options)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = createTable(ctx, tx, name, options.WithDocument(schema))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = createTableIndexes(ctx, tx, name, schema, options.Indexes)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = createChangesTable(ctx, tx, name)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	err = createTable(ctx, tx, name, schema)
+	err = createAttachmentsTable(ctx, tx, name)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	for _, field := range schema {
-		if field.Indexed {
-			err = createIndex(ctx, tx, name, field.Name)
-			if err != nil {
-				tx.Rollback()
-				return nil, err
-			}
+	if options.History {
+		err = createHistoryTable(ctx, tx, name, options.WithDocument(schema))
+		if err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 	}
 
+	err = createSearchTable(ctx, tx, name, schema)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		log.Printf("Error creating bucket %v: %v\n", name, err)
@@ -90,28 +139,30 @@ func (r *sqlRepo) NewBucket(ctx context.Context, name string, schema []model.Fie
 	}
 
 	bucket := bucket{
-		repo:   r,
-		name:   name,
-		schema: schema,
+		repo:    r,
+		name:    name,
+		schema:  schema,
+		options: options,
 	}
 
 	return &bucket, nil
 }
 
 func (r *sqlRepo) GetBucket(ctx context.Context, name string) (repo.Bucket, error) {
-	schema, err := readSchema(ctx, r.db, name)
-	if err != nil {
-		return nil, err
+	schema, options, err := readDefinition(ctx, r.db, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
 
-	if schema == nil {
-		return nil, nil
+	if err != nil {
+		return nil, err
 	}
 
 	bucket := bucket{
-		repo:   r,
-		name:   name,
-		schema: schema,
+		repo:    r,
+		name:    name,
+		schema:  schema,
+		options: options,
 	}
 
 	return &bucket, nil
@@ -144,6 +195,30 @@ func (r *sqlRepo) DropBucket(ctx context.Context, name string) error {
 		return err
 	}
 
+	err = dropHistoryTable(ctx, tx, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = dropChangesTable(ctx, tx, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = dropAttachmentsTable(ctx, tx, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = dropSearchTable(ctx, tx, name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		log.Printf("Error removing bucket %v: %v\n", name, err)
@@ -152,3 +227,65 @@ func (r *sqlRepo) DropBucket(ctx context.Context, name string) error {
 
 	return nil
 }
+
+func (r *sqlRepo) AlterBucket(ctx context.Context, name string, change model.SchemaChange) (repo.Bucket, error) {
+	schema, options, err := readDefinition(ctx, r.db, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.BucketNotFound.New(name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	newSchema, err := alterTable(ctx, tx, name, schema, options, change)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !r.fullText && len(model.SearchableFields(newSchema)) > 0 {
+		tx.Rollback()
+		return nil, apperror.SearchNotSupported.New()
+	}
+
+	err = updateBucketOnCatalog(ctx, tx, name, newSchema)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		log.Printf("Error altering bucket %v: %v\n", name, err)
+		return nil, err
+	}
+
+	bucket := bucket{
+		repo:    r,
+		name:    name,
+		schema:  newSchema,
+		options: options,
+	}
+
+	return &bucket, nil
+}
+
+func (r *sqlRepo) Begin(ctx context.Context) (repo.Transaction, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := transaction{
+		repo: r,
+		tx:   tx,
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,71 @@
+package relational
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+	"github.com/jjmrocha/oblivion/repo"
+)
+
+func newTestRepo(t *testing.T) repo.Repository {
+	t.Helper()
+
+	repo := New("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(repo.Close)
+
+	return repo
+}
+
+func isErrorType(err error, errorType apperror.ErrorType) bool {
+	var appErr *apperror.Error
+	return errors.As(err, &appErr) && appErr.ErrorType == errorType
+}
+
+func TestSchemalessDocumentBucket(t *testing.T) {
+	ctx := context.Background()
+
+	repo := newTestRepo(t)
+
+	options := model.BucketOptions{Mode: model.DocumentMode}
+	if _, err := repo.NewBucket(ctx, "documents", nil, options); err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	bucket, err := repo.GetBucket(ctx, "documents")
+	if err != nil {
+		t.Fatalf("reading bucket: %v", err)
+	}
+
+	if bucket == nil {
+		t.Fatal("bucket not found")
+	}
+
+	if bucket.Schema() == nil || len(bucket.Schema()) != 0 {
+		t.Errorf("expected an empty schema, got %v", bucket.Schema())
+	}
+
+	if !bucket.Options().IsDocument() {
+		t.Errorf("expected a document bucket, got mode %q", bucket.Options().Mode)
+	}
+
+	if _, err = bucket.Store(ctx, "k1", model.Object{"color": "red"}, model.Precondition{}, 0); err != nil {
+		t.Fatalf("storing value: %v", err)
+	}
+
+	value, version, err := bucket.Read(ctx, "k1")
+	if err != nil {
+		t.Fatalf("reading value: %v", err)
+	}
+
+	if version != 1 || value["color"] != "red" {
+		t.Errorf("unexpected value %v with version %v", value, version)
+	}
+
+	if _, err = repo.NewBucket(ctx, "documents", nil, options); err == nil {
+		t.Error("expected an error creating the bucket again")
+	}
+}
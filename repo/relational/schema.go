@@ -6,13 +6,33 @@ import (
 	"github.com/jjmrocha/oblivion/model"
 )
 
+// unmarshalSchema always returns a schema, document buckets may have been stored with a null schema
 func unmarshalSchema(data []byte) ([]model.Field, error) {
 	schema := make([]model.Field, 0)
 	err := json.Unmarshal(data, &schema)
+	if schema == nil {
+		schema = make([]model.Field, 0)
+	}
+
 	return schema, err
 }
 
 func marshalSchema(schema []model.Field) ([]byte, error) {
+	if schema == nil {
+		schema = make([]model.Field, 0)
+	}
+
 	data, err := json.Marshal(schema)
 	return data, err
 }
+
+func unmarshalOptions(data []byte) (model.BucketOptions, error) {
+	var options model.BucketOptions
+	err := json.Unmarshal(data, &options)
+	return options, err
+}
+
+func marshalOptions(options model.BucketOptions) ([]byte, error) {
+	data, err := json.Marshal(options)
+	return data, err
+}
@@ -0,0 +1,129 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+
+	"github.com/jjmrocha/oblivion/model"
+)
+
+const searchKeyColumn = "_search_key"
+
+func searchTable(tableName string) string {
+	return "_search_" + tableName
+}
+
+func fullTextSupported(ctx context.Context, db *sql.DB) (bool, error) {
+	var enabled bool
+
+	err := db.QueryRowContext(ctx, "select sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled)
+	return enabled, err
+}
+
+// the values of all searchable fields are indexed together on a single column
+func createSearchTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field) error {
+	if len(model.SearchableFields(schema)) == 0 {
+		return nil
+	}
+
+	query := "create virtual table " + searchTable(tableName) + " using fts5(key unindexed, content)"
+
+	_, err := tx.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return indexValues(ctx, tx, tableName, schema, "1 = 1")
+}
+
+func dropSearchTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "drop table if exists " + searchTable(tableName)
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func rebuildSearchTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field) error {
+	err := dropSearchTable(ctx, tx, tableName)
+	if err != nil {
+		return err
+	}
+
+	return createSearchTable(ctx, tx, tableName, schema)
+}
+
+// indexValues replaces the indexed content of the keys selected by where
+func indexValues(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field, where string, args ...any) error {
+	fields := model.SearchableFields(schema)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	err := unindexValues(ctx, tx, tableName, schema, where, args...)
+	if err != nil {
+		return err
+	}
+
+	content := make([]string, 0, len(fields))
+	for _, field := range fields {
+		content = append(content, "coalesce("+field+", '')")
+	}
+
+	query := "insert into " + searchTable(tableName) + " (key, content)" +
+		" select key, " + strings.Join(content, " || ' ' || ") + " from " + tableName + " where " + where
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+func unindexValues(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field, where string, args ...any) error {
+	if len(model.SearchableFields(schema)) == 0 {
+		return nil
+	}
+
+	query := "delete from " + searchTable(tableName) + " where key in (select key from " + tableName + " where " + where + ")"
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// searchSource joins the bucket table with the keys matching the terms, exposing their relevance as model.RankField
+func searchSource(bucket *bucket, terms []string) (string, []any) {
+	if len(terms) == 0 {
+		return bucket.name, nil
+	}
+
+	search := searchTable(bucket.name)
+	source := bucket.name + " join (select key as " + searchKeyColumn + ", rank as " + model.RankField +
+		" from " + search + " where " + search + " match ?) on " + searchKeyColumn + " = key"
+
+	return source, []any{matchExpression(terms)}
+}
+
+// every term is quoted, so it can't be interpreted as FTS5 syntax, a trailing * is kept as prefix search
+func matchExpression(terms []string) string {
+	phrases := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if len(term) == 0 {
+			continue
+		}
+
+		phrase := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			phrase += "*"
+		}
+
+		phrases = append(phrases, phrase)
+	}
+
+	return strings.Join(phrases, " ")
+}
+
+func searchableChanged(schema []model.Field, newSchema []model.Field) bool {
+	return !slices.Equal(model.SearchableFields(schema), model.SearchableFields(newSchema))
+}
@@ -0,0 +1,69 @@
+package relational
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jjmrocha/oblivion/apperror"
+	"github.com/jjmrocha/oblivion/model"
+)
+
+// full-text search needs sqlite built with the sqlite_fts5 tag
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+
+	schema := []model.Field{
+		{Name: "name", Type: model.StringDataType},
+		{Name: "description", Type: model.StringDataType, Searchable: true},
+	}
+
+	bucket, err := repo.NewBucket(ctx, "products", schema, model.BucketOptions{})
+	if isErrorType(err, apperror.SearchNotSupported) {
+		t.Skip("full-text search not supported, build with -tags sqlite_fts5")
+	}
+
+	if err != nil {
+		t.Fatalf("creating bucket: %v", err)
+	}
+
+	values := map[string]model.Object{
+		"k1": {"name": "apple", "description": "a red fruit"},
+		"k2": {"name": "sky", "description": "blue and wide"},
+		"k3": {"name": "blueberry", "description": "a blue fruit"},
+	}
+
+	for key, value := range values {
+		if _, err = bucket.Store(ctx, key, value, model.Precondition{}, 0); err != nil {
+			t.Fatalf("storing %v: %v", key, err)
+		}
+	}
+
+	cases := []struct {
+		terms []string
+		keys  []string
+	}{
+		{terms: []string{"fruit"}, keys: []string{"k1", "k3"}},
+		{terms: []string{"blue", "fruit"}, keys: []string{"k3"}},
+		{terms: []string{"wid*"}, keys: []string{"k2"}},
+		{terms: []string{`"red" OR blue`}, keys: []string{}},
+	}
+
+	for _, c := range cases {
+		page, err := bucket.Search(ctx, model.Query{Search: c.terms})
+		if err != nil {
+			t.Errorf("searching %q: %v", c.terms, err)
+			continue
+		}
+
+		keys := make([]string, 0, len(page.Items))
+		for _, entry := range page.Items {
+			keys = append(keys, entry.Key)
+		}
+
+		if !slices.Equal(keys, c.keys) {
+			t.Errorf("searching %q found %v, expected %v", c.terms, keys, c.keys)
+		}
+	}
+}
@@ -3,47 +3,104 @@ package relational
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/jjmrocha/oblivion/apperror"
 	"github.com/jjmrocha/oblivion/model"
+	"github.com/mattn/go-sqlite3"
 )
 
-func readSchema(ctx context.Context, db *sql.DB, bucket string) ([]model.Field, error) {
-	stm, err := db.PrepareContext(ctx, "select schema from oblivion where bucket_name = ?")
+const (
+	versionColumn  = "_version"
+	expiresColumn  = "_expires_at"
+	modifiedColumn = "_modified_at"
+)
+
+type dbConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func readDefinition(ctx context.Context, db dbConn, bucket string) ([]model.Field, model.BucketOptions, error) {
+	var options model.BucketOptions
+
+	stm, err := db.PrepareContext(ctx, "select schema, options from oblivion where bucket_name = ?")
 	if err != nil {
-		return nil, err
+		return nil, options, err
 	}
 	defer stm.Close()
 
 	row := stm.QueryRowContext(ctx, bucket)
 
-	var schemaStr string
-	if err = row.Scan(&schemaStr); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-
-		return nil, err
+	// sql.ErrNoRows is returned when the bucket doesn't exist
+	var schemaStr, optionsStr string
+	if err = row.Scan(&schemaStr, &optionsStr); err != nil {
+		return nil, options, err
 	}
 
 	schema, err := unmarshalSchema([]byte(schemaStr))
 	if err != nil {
-		return nil, err
+		return nil, options, err
 	}
 
-	return schema, nil
+	options, err = unmarshalOptions([]byte(optionsStr))
+	if err != nil {
+		return nil, options, err
+	}
+
+	return schema, options, nil
 }
 
 func buildFindByKeySql(bucket *bucket) string {
-	columns := make([]string, 0, len(bucket.schema))
-	for _, field := range bucket.schema {
-		columns = append(columns, field.Name)
+	columnList := strings.Join(fieldNames(bucket.columns()), ", ")
+	query := "select " + versionColumn + ", " + columnList + " from " + bucket.name + " where key = ? and " + notExpired()
+
+	return query
+}
+
+func fieldNames(schema []model.Field) []string {
+	names := make([]string, 0, len(schema))
+	for _, field := range schema {
+		names = append(names, field.Name)
 	}
 
-	columnList := strings.Join(columns, ", ")
-	query := "select " + columnList + " from " + bucket.name + " where key = ?"
+	return names
+}
 
-	return query
+func readValue(ctx context.Context, db dbConn, bucket *bucket, key string) (model.Object, int64, error) {
+	query := buildFindByKeySql(bucket)
+	stm, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer stm.Close()
+
+	row := stm.QueryRowContext(ctx, key, now())
+	return scanValue(row, bucket.columns())
+}
+
+func scanValue(row *sql.Row, schema []model.Field) (model.Object, int64, error) {
+	var version int64
+	values := valuesForScan(schema)
+
+	err := row.Scan(append([]any{&version}, values...)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, 0, nil
+		}
+
+		return nil, 0, err
+	}
+
+	obj := buildObject(schema, values)
+	return obj, version, nil
 }
 
 func buildObject(schema []model.Field, values []any) model.Object {
@@ -51,11 +108,16 @@ func buildObject(schema []model.Field, values []any) model.Object {
 
 	for i, field := range schema {
 		switch field.Type {
-		case model.StringDataType:
+		case model.StringDataType, model.TimestampDataType, model.DateDataType, model.EnumDataType:
 			holder := values[i].(*sql.NullString)
 			if holder.Valid {
 				obj[field.Name] = holder.String
 			}
+		case model.IntegerDataType:
+			holder := values[i].(*sql.NullInt64)
+			if holder.Valid {
+				obj[field.Name] = holder.Int64
+			}
 		case model.NumberDataType:
 			holder := values[i].(*sql.NullFloat64)
 			if holder.Valid {
@@ -66,165 +128,543 @@ func buildObject(schema []model.Field, values []any) model.Object {
 			if holder.Valid {
 				obj[field.Name] = holder.Bool
 			}
+		case model.ObjectDataType, model.ArrayDataType:
+			holder := values[i].(*sql.NullString)
+			if holder.Valid {
+				obj[field.Name] = decodeJSON(field, holder.String)
+			}
+		case model.BinaryDataType:
+			holder := values[i].(*[]byte)
+			if *holder != nil {
+				obj[field.Name] = *holder
+			}
+		}
+	}
+
+	// undeclared fields of documents are returned with the declared ones
+	if document, ok := obj[model.DocumentField].(map[string]any); ok {
+		delete(obj, model.DocumentField)
+
+		for name, value := range document {
+			if _, found := obj[name]; !found {
+				obj[name] = value
+			}
 		}
 	}
 
 	return obj
 }
 
+// splitDocument moves the undeclared fields of document buckets to the document field
+func splitDocument(bucket *bucket, obj model.Object) model.Object {
+	if !bucket.options.IsDocument() {
+		return obj
+	}
+
+	row := make(model.Object, len(obj))
+	document := make(map[string]any)
+
+	for name, value := range obj {
+		if _, found := model.FieldByName(bucket.schema, name); found {
+			row[name] = value
+		} else {
+			document[name] = value
+		}
+	}
+
+	if len(document) > 0 {
+		row[model.DocumentField] = document
+	}
+
+	return row
+}
+
+// decodeJSON reads the value of object and array fields, numbers are kept exact and normalized using the field
+func decodeJSON(field model.Field, text string) any {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+
+	return field.Normalize(value)
+}
+
+// columnValue returns the value to store on the column of a field, objects and arrays are stored as JSON
+func columnValue(field model.Field, value any) (any, error) {
+	if !field.Type.IsJSON() || value == nil {
+		return value, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
 func valuesForScan(schema []model.Field) []any {
 	values := make([]any, len(schema))
 
 	for i, field := range schema {
 		switch field.Type {
-		case model.StringDataType:
+		case model.StringDataType, model.TimestampDataType, model.DateDataType, model.EnumDataType,
+			model.ObjectDataType, model.ArrayDataType:
 			var holder sql.NullString
 			values[i] = &holder
+		case model.IntegerDataType:
+			var holder sql.NullInt64
+			values[i] = &holder
 		case model.NumberDataType:
 			var holder sql.NullFloat64
 			values[i] = &holder
 		case model.BoolDataType:
 			var holder sql.NullBool
 			values[i] = &holder
+		case model.BinaryDataType:
+			var holder []byte
+			values[i] = &holder
 		}
 	}
 
 	return values
 }
 
-func buildSearchQuery(bucket *bucket, criteria model.Criteria) (string, []any) {
-	where := ""
-	values := make([]any, 0, len(criteria))
+func bucketExists(ctx context.Context, db *sql.DB, bucket string) (bool, error) {
+	_, _, err := readDefinition(ctx, db, bucket)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 
-	for field, valueList := range criteria {
-		if len(where) > 0 {
-			where += " and "
-		}
+	if err != nil {
+		return false, err
+	}
 
-		or := ""
+	return true, nil
+}
 
-		for _, option := range valueList {
-			if len(or) > 0 {
-				or += " or "
-			}
+func createTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field) error {
+	query := "create table " + tableName + " (key varchar(50) primary key, " + versionColumn + " integer not null, " + modifiedColumn + " integer not null, " + expiresColumn + " integer"
+	for _, field := range schema {
+		query += " , " + columnDefinition(field)
+	}
+	query += ")"
 
-			or += field + " = ?"
-			values = append(values, option)
-		}
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
 
-		where += "(" + or + ")"
+func columnDefinition(field model.Field) string {
+	definition := field.Name
+
+	switch field.Type {
+	case model.StringDataType:
+		definition += " text"
+	case model.NumberDataType:
+		definition += " numeric"
+	case model.BoolDataType:
+		definition += " boolean"
+	case model.IntegerDataType:
+		definition += " integer"
+	case model.TimestampDataType, model.DateDataType, model.EnumDataType, model.ObjectDataType, model.ArrayDataType:
+		definition += " text"
+	case model.BinaryDataType:
+		definition += " blob"
 	}
 
-	query := "select key from " + bucket.name
-
-	if len(where) > 0 {
-		query += " where " + where
+	if field.Required {
+		definition += " not null"
 	}
 
-	return query, values
+	return definition
 }
 
-func bucketExists(ctx context.Context, db *sql.DB, bucket string) (bool, error) {
-	schema, err := readSchema(ctx, db, bucket)
-	if err != nil {
-		return false, err
-	}
+func dropTable(ctx context.Context, tx *sql.Tx, tableName string) error {
+	query := "drop table " + tableName
 
-	exists := schema != nil
-	return exists, nil
+	_, err := tx.ExecContext(ctx, query)
+	return err
 }
 
-func createTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field) error {
-	query := "create table " + tableName + " (key varchar(50) primary key"
-	for _, field := range schema {
-		query += " , " + field.Name
+func createTableIndexes(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field, indexes []model.Index) error {
+	err := createIndex(ctx, tx, tableName, expiresColumn)
+	if err != nil {
+		return err
+	}
 
-		switch field.Type {
-		case model.StringDataType:
-			query += " text"
-		case model.NumberDataType:
-			query += " numeric"
-		case model.BoolDataType:
-			query += " boolean"
+	for _, field := range schema {
+		if field.Indexed {
+			err = createIndex(ctx, tx, tableName, field.Name)
+			if err != nil {
+				return err
+			}
 		}
+	}
 
-		if field.Required {
-			query += " not null"
+	for _, index := range indexes {
+		err = createBucketIndex(ctx, tx, tableName, index)
+		if err != nil {
+			return err
 		}
 	}
-	query += ")"
+
+	return nil
+}
+
+func createBucketIndex(ctx context.Context, tx *sql.Tx, tableName string, index model.Index) error {
+	query := "create index "
+	if index.Unique {
+		query = "create unique index "
+	}
+
+	query += bucketIndexName(tableName, index.Name) + " on " + tableName + " (" + strings.Join(index.Fields, ", ") + ")"
 
 	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func dropTable(ctx context.Context, tx *sql.Tx, tableName string) error {
-	query := "drop table " + tableName
+func createIndex(ctx context.Context, tx *sql.Tx, tableName string, column string) error {
+	query := "create index " + indexName(tableName, column) + " on " + tableName + " (" + column + ")"
 
 	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func createIndex(ctx context.Context, tx *sql.Tx, tableName string, column string) error {
-	indexName := "i_" + tableName + "_" + column
-	query := "create index " + indexName + " on " + tableName + " (" + column + ")"
+// bucket indexes use a different prefix, so their names don't collide with the field indexes
+func bucketIndexName(tableName string, name string) string {
+	return "x_" + tableName + "_" + name
+}
+
+func indexName(tableName string, column string) string {
+	return "i_" + tableName + "_" + column
+}
+
+func dropIndex(ctx context.Context, tx *sql.Tx, tableName string, column string) error {
+	query := "drop index " + indexName(tableName, column)
 
 	_, err := tx.ExecContext(ctx, query)
 	return err
 }
 
-func updateValue(ctx context.Context, db *sql.DB, bucket *bucket, key string, obj model.Object) error {
-	columnList := ""
-	values := make([]any, 0)
+func addColumn(ctx context.Context, tx *sql.Tx, tableName string, field model.Field) error {
+	query := "alter table " + tableName + " add column " + columnDefinition(field)
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func dropColumn(ctx context.Context, tx *sql.Tx, tableName string, column string) error {
+	query := "alter table " + tableName + " drop column " + column
+
+	_, err := tx.ExecContext(ctx, query)
+	return err
+}
+
+func countRows(ctx context.Context, tx *sql.Tx, tableName string, where string) (int, error) {
+	query := "select count(*) from " + tableName
+	if len(where) > 0 {
+		query += " where " + where
+	}
+
+	row := tx.QueryRowContext(ctx, query)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SQLite doesn't support changing column constraints, the table must be recreated
+func rebuildTable(ctx context.Context, tx *sql.Tx, tableName string, schema []model.Field, indexes []model.Index) error {
+	tmpTableName := "_rebuild_" + tableName
+
+	err := createTable(ctx, tx, tmpTableName, schema)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"key", versionColumn, modifiedColumn, expiresColumn}
+	columns = append(columns, fieldNames(schema)...)
+
+	columnList := strings.Join(columns, ", ")
+	query := "insert into " + tmpTableName + " (" + columnList + ") select " + columnList + " from " + tableName
+
+	_, err = tx.ExecContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	err = dropTable(ctx, tx, tableName)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "alter table "+tmpTableName+" rename to "+tableName)
+	if err != nil {
+		return err
+	}
+
+	return createTableIndexes(ctx, tx, tableName, schema, indexes)
+}
+
+func applyOperation(ctx context.Context, tx *sql.Tx, bucket *bucket, operation model.Operation) (int64, error) {
+	switch operation.Type {
+	case model.SetOperation:
+		ttl := time.Duration(operation.TTL) * time.Second
+		return storeValue(ctx, tx, bucket, operation.Key, operation.Value, model.Precondition{}, ttl)
+	case model.DeleteOperation:
+		return 0, deleteValue(ctx, tx, bucket, operation.Key, model.Precondition{})
+	case model.CheckOperation:
+		return 0, checkCondition(ctx, tx, bucket, operation.Key, operation.Condition)
+	}
+
+	return 0, apperror.InvalidOperation.New(operation.Type)
+}
+
+func checkCondition(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, condition *model.Condition) error {
+	if condition == nil {
+		return apperror.InvalidCondition.New(key)
+	}
+
+	obj, _, err := readValue(ctx, tx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if !condition.Check(obj) {
+		return apperror.ConditionFailed.New(key, bucket.name)
+	}
+
+	return nil
+}
+
+func storeValue(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, obj model.Object, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	version, err := keyVersion(ctx, tx, bucket, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if !precondition.Check(version) {
+		return 0, apperror.PreconditionFailed.New(key, bucket.name)
+	}
+
+	if ttl == 0 {
+		ttl = bucket.options.TTL()
+	}
 
-	for _, field := range bucket.schema {
-		if len(columnList) > 0 {
-			columnList += ", "
+	expiresAt := expiration(ttl)
+
+	if version == 0 {
+		err = insertValue(ctx, tx, bucket, key, obj, expiresAt)
+		if err != nil {
+			return 0, err
 		}
 
-		value, found := obj[field.Name]
+		return 1, recordChange(ctx, tx, bucket, key, model.SetOperation, 1, obj)
+	}
+
+	err = archiveValues(ctx, tx, bucket, "?", "key = ?", now(), key)
+	if err != nil {
+		return 0, err
+	}
+
+	updated, err := updateValue(ctx, tx, bucket, key, obj, version, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	if !updated {
+		return 0, apperror.PreconditionFailed.New(key, bucket.name)
+	}
+
+	return version + 1, recordChange(ctx, tx, bucket, key, model.SetOperation, version+1, obj)
+}
+
+func deleteValue(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, precondition model.Precondition) error {
+	version, err := keyVersion(ctx, tx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if !precondition.Check(version) {
+		return apperror.PreconditionFailed.New(key, bucket.name)
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	err = archiveValues(ctx, tx, bucket, "?", "key = ?", now(), key)
+	if err != nil {
+		return err
+	}
+
+	err = recordChange(ctx, tx, bucket, key, model.DeleteOperation, version, nil)
+	if err != nil {
+		return err
+	}
+
+	err = unindexValues(ctx, tx, bucket.name, bucket.schema, "key = ?", key)
+	if err != nil {
+		return err
+	}
+
+	err = deleteAttachments(ctx, tx, bucket, "key = ?", key)
+	if err != nil {
+		return err
+	}
+
+	query := "delete from " + bucket.name + " where key = ?"
+	_, err = tx.ExecContext(ctx, query, key)
+	return err
+}
+
+func updateValue(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, obj model.Object, version int64, expiresAt any) (bool, error) {
+	set := versionColumn + " = " + versionColumn + " + 1, " + modifiedColumn + " = ?, " + expiresColumn + " = ?"
+
+	return updateColumns(ctx, tx, bucket, key, obj, bucket.columns(), version, set, now(), expiresAt)
+}
+
+// updateColumns sets the columns of the given fields to their values on the object, or to null when missing,
+// after the assignments on set, which use the given values
+func updateColumns(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, obj model.Object, fields []model.Field, version int64, set string, values ...any) (bool, error) {
+	columnList := set
+	row := splitDocument(bucket, obj)
+
+	for _, field := range fields {
+		columnList += ", "
+
+		value, found := row[field.Name]
 
 		if found {
+			converted, err := columnValue(field, value)
+			if err != nil {
+				return false, err
+			}
+
 			columnList += field.Name + " = ?"
-			values = append(values, value)
+			values = append(values, converted)
 		} else {
 			columnList += field.Name + " = null"
 		}
 	}
 
-	values = append(values, key)
+	values = append(values, key, version)
 
-	query := "update " + bucket.name + " set " + columnList + " where key = ?"
+	query := "update " + bucket.name + " set " + columnList + " where key = ? and " + versionColumn + " = ?"
 
-	stm, err := db.PrepareContext(ctx, query)
+	stm, err := tx.PrepareContext(ctx, query)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	defer stm.Close()
 
-	_, err = stm.ExecContext(ctx, values...)
+	result, err := stm.ExecContext(ctx, values...)
+	if err != nil {
+		return false, uniqueViolation(bucket, key, err)
+	}
 
-	return err
+	count, err := result.RowsAffected()
+	if err != nil || count == 0 {
+		return false, err
+	}
+
+	return true, indexValues(ctx, tx, bucket.name, bucket.schema, "key = ?", key)
 }
 
-func insertValue(ctx context.Context, db *sql.DB, bucket *bucket, key string, obj model.Object) error {
-	columnCount := len(obj)
+// patchValue stores the new value of an existing key writing only the columns of the changed fields,
+// the expiration is kept unless a ttl is given
+func patchValue(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, obj model.Object, changed []string, precondition model.Precondition, ttl time.Duration) (int64, error) {
+	version, err := keyVersion(ctx, tx, bucket, key)
+	if err != nil {
+		return 0, err
+	}
 
-	columns := make([]string, 0, columnCount)
-	values := make([]any, 0, columnCount+1)
-	values = append(values, key)
+	if version == 0 {
+		return 0, apperror.KeyNotFound.New(key, bucket.name)
+	}
+
+	if !precondition.Check(version) {
+		return 0, apperror.PreconditionFailed.New(key, bucket.name)
+	}
+
+	err = archiveValues(ctx, tx, bucket, "?", "key = ?", now(), key)
+	if err != nil {
+		return 0, err
+	}
+
+	set := versionColumn + " = " + versionColumn + " + 1, " + modifiedColumn + " = ?"
+	values := []any{now()}
+
+	if ttl > 0 {
+		set += ", " + expiresColumn + " = ?"
+		values = append(values, expiration(ttl))
+	}
+
+	updated, err := updateColumns(ctx, tx, bucket, key, obj, changedColumns(bucket, changed), version, set, values...)
+	if err != nil {
+		return 0, err
+	}
 
-	for field, value := range obj {
-		columns = append(columns, field)
-		values = append(values, value)
+	if !updated {
+		return 0, apperror.PreconditionFailed.New(key, bucket.name)
+	}
+
+	return version + 1, recordChange(ctx, tx, bucket, key, model.SetOperation, version+1, obj)
+}
+
+// changedColumns are the columns storing the changed fields, undeclared fields of documents are stored on the document field
+func changedColumns(bucket *bucket, changed []string) []model.Field {
+	columns := make([]model.Field, 0, len(changed))
+	document := false
+
+	for _, name := range changed {
+		if field, found := model.FieldByName(bucket.schema, name); found {
+			columns = append(columns, field)
+		} else {
+			document = true
+		}
+	}
+
+	if document && bucket.options.IsDocument() {
+		columns = append(columns, model.Field{Name: model.DocumentField, Type: model.ObjectDataType})
+	}
+
+	return columns
+}
+
+func insertValue(ctx context.Context, tx *sql.Tx, bucket *bucket, key string, obj model.Object, expiresAt any) error {
+	row := splitDocument(bucket, obj)
+	columnCount := len(row)
+
+	columns := make([]string, 0, columnCount+3)
+	columns = append(columns, versionColumn, modifiedColumn, expiresColumn)
+	values := make([]any, 0, columnCount+4)
+	values = append(values, key, 1, now(), expiresAt)
+
+	for name, value := range row {
+		field, _ := model.FieldByName(bucket.columns(), name)
+
+		converted, err := columnValue(field, value)
+		if err != nil {
+			return err
+		}
+
+		columns = append(columns, name)
+		values = append(values, converted)
 	}
 
 	columnList := strings.Join(columns, ", ")
-	paramList := strings.Join(strings.Split(strings.Repeat("?", columnCount), ""), ", ")
+	paramList := strings.Join(strings.Split(strings.Repeat("?", len(columns)), ""), ", ")
 	query := "insert into " + bucket.name + " (key, " + columnList + ") values (?, " + paramList + ")"
 
-	stm, err := db.PrepareContext(ctx, query)
+	stm, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
@@ -232,25 +672,164 @@ func insertValue(ctx context.Context, db *sql.DB, bucket *bucket, key string, ob
 	defer stm.Close()
 
 	_, err = stm.ExecContext(ctx, values...)
+	if err != nil {
+		return uniqueViolation(bucket, key, err)
+	}
+
+	return indexValues(ctx, tx, bucket.name, bucket.schema, "key = ?", key)
+}
+
+// uniqueViolation maps the violation of an unique index to an application error naming the index,
+// the index is the one with the same columns sqlite reports, in any order
+func uniqueViolation(bucket *bucket, key string, err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return err
+	}
+
+	columns := violatedColumns(bucket, sqliteErr)
+
+	for _, index := range bucket.options.Indexes {
+		if !index.Unique {
+			continue
+		}
+
+		fields := slices.Clone(index.Fields)
+		slices.Sort(fields)
+
+		if slices.Equal(fields, columns) {
+			return apperror.UniqueViolation.New(key, index.Name, bucket.name)
+		}
+	}
 
 	return err
 }
 
-func keyExists(ctx context.Context, db *sql.DB, bucket *bucket, key string) (bool, error) {
-	query := "select count(*) from " + bucket.name + " where key = ?"
-	stm, err := db.PrepareContext(ctx, query)
+// violatedColumns returns the sorted columns of the violated constraint, sqlite lists them as table.column after the last colon
+func violatedColumns(bucket *bucket, sqliteErr sqlite3.Error) []string {
+	message := sqliteErr.Error()
+	list := message[strings.LastIndex(message, ":")+1:]
+	columns := make([]string, 0)
+
+	for _, column := range strings.Split(list, ",") {
+		columns = append(columns, strings.TrimPrefix(strings.TrimSpace(column), bucket.name+"."))
+	}
+
+	slices.Sort(columns)
+	return columns
+}
+
+func keyVersion(ctx context.Context, tx *sql.Tx, bucket *bucket, key string) (int64, error) {
+	err := deleteExpiredKey(ctx, tx, bucket, key)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	defer stm.Close()
 
-	row := stm.QueryRowContext(ctx, key)
+	query := "select " + versionColumn + " from " + bucket.name + " where key = ?"
+	row := tx.QueryRowContext(ctx, query, key)
 
-	var count int
-	if err = row.Scan(&count); err != nil {
-		return false, err
+	var version int64
+	if err := row.Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+
+		return 0, err
 	}
 
-	exists := count > 0
-	return exists, nil
+	return version, nil
+}
+
+func deleteExpiredKey(ctx context.Context, tx *sql.Tx, bucket *bucket, key string) error {
+	where := "key = ? and " + expiresColumn + " <= ?"
+	timestamp := now()
+
+	err := archiveValues(ctx, tx, bucket, expiresColumn, where, key, timestamp)
+	if err != nil {
+		return err
+	}
+
+	err = recordDeletes(ctx, tx, bucket, where, key, timestamp)
+	if err != nil {
+		return err
+	}
+
+	err = unindexValues(ctx, tx, bucket.name, bucket.schema, where, key, timestamp)
+	if err != nil {
+		return err
+	}
+
+	err = deleteAttachments(ctx, tx, bucket, where, key, timestamp)
+	if err != nil {
+		return err
+	}
+
+	query := "delete from " + bucket.name + " where " + where
+
+	_, err = tx.ExecContext(ctx, query, key, timestamp)
+	return err
+}
+
+func deleteExpiredKeys(ctx context.Context, db *sql.DB, bucket *bucket, limit int) (int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	where := "key in (select key from " + bucket.name + " where " + expiresColumn + " <= ? order by key limit ?)"
+	timestamp := now()
+
+	err = archiveValues(ctx, tx, bucket, expiresColumn, where, timestamp, limit)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = recordDeletes(ctx, tx, bucket, where, timestamp, limit)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = unindexValues(ctx, tx, bucket.name, bucket.schema, where, timestamp, limit)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	err = deleteAttachments(ctx, tx, bucket, where, timestamp, limit)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	query := "delete from " + bucket.name + " where " + where
+
+	result, err := tx.ExecContext(ctx, query, timestamp, limit)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func notExpired() string {
+	return "(" + expiresColumn + " is null or " + expiresColumn + " > ?)"
+}
+
+func expiration(ttl time.Duration) any {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return time.Now().Add(ttl).UnixMilli()
+}
+
+func now() int64 {
+	return time.Now().UnixMilli()
 }
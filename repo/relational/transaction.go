@@ -0,0 +1,43 @@
+package relational
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jjmrocha/oblivion/repo"
+)
+
+type transaction struct {
+	repo *sqlRepo
+	tx   *sql.Tx
+}
+
+func (t *transaction) Bucket(ctx context.Context, name string) (repo.Bucket, error) {
+	schema, options, err := readDefinition(ctx, t.tx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := bucket{
+		repo:    t.repo,
+		tx:      t.tx,
+		name:    name,
+		schema:  schema,
+		options: options,
+	}
+
+	return &bucket, nil
+}
+
+func (t *transaction) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *transaction) Rollback() error {
+	return t.tx.Rollback()
+}
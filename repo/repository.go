@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/jjmrocha/oblivion/model"
 )
@@ -9,16 +10,38 @@ import (
 type Repository interface {
 	Close()
 	BucketNames(ctx context.Context) ([]string, error)
-	NewBucket(ctx context.Context, name string, schema []model.Field) (Bucket, error)
+	NewBucket(ctx context.Context, name string, schema []model.Field, options model.BucketOptions) (Bucket, error)
 	GetBucket(ctx context.Context, name string) (Bucket, error)
 	DropBucket(ctx context.Context, name string) error
+	AlterBucket(ctx context.Context, name string, change model.SchemaChange) (Bucket, error)
+	Begin(ctx context.Context) (Transaction, error)
+}
+
+type Transaction interface {
+	Bucket(ctx context.Context, name string) (Bucket, error)
+	Commit() error
+	Rollback() error
 }
 
 type Bucket interface {
 	Name() string
 	Schema() []model.Field
-	Store(ctx context.Context, key string, value model.Object) error
-	Read(ctx context.Context, key string) (model.Object, error)
-	Delete(ctx context.Context, key string) error
-	Keys(ctx context.Context, criteria model.Criteria) ([]string, error)
+	Options() model.BucketOptions
+	Store(ctx context.Context, key string, value model.Object, precondition model.Precondition, ttl time.Duration) (int64, error)
+	// Update stores the new value of an existing key, writing only the changed fields
+	Update(ctx context.Context, key string, value model.Object, changed []string, precondition model.Precondition, ttl time.Duration) (int64, error)
+	Read(ctx context.Context, key string) (model.Object, int64, error)
+	ReadAt(ctx context.Context, key string, at time.Time) (model.Object, int64, error)
+	History(ctx context.Context, key string) ([]model.Revision, error)
+	Delete(ctx context.Context, key string, precondition model.Precondition) error
+	StoreAttachment(ctx context.Context, key string, attachment model.Attachment) error
+	ReadAttachment(ctx context.Context, key string, name string) (*model.Attachment, error)
+	DeleteAttachment(ctx context.Context, key string, name string) (bool, error)
+	Keys(ctx context.Context, query model.Query) (model.Page[string], error)
+	Search(ctx context.Context, query model.Query) (model.Page[model.Entry], error)
+	Aggregate(ctx context.Context, aggregation model.Aggregation) ([]model.AggregateResult, error)
+	// Apply applies an operation of a batch or transaction, on the transaction of the bucket when it has one
+	Apply(ctx context.Context, operation model.Operation) (int64, error)
+	Changes(ctx context.Context, since int64, limit int) ([]model.Change, error)
+	LastChange(ctx context.Context) (int64, error)
 }
@@ -1,8 +1,11 @@
 package valid
 
 import (
+	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 
 	"github.com/jjmrocha/oblivion/apperror"
 	"github.com/jjmrocha/oblivion/model"
@@ -12,12 +15,14 @@ const (
 	_BucketNameRegExp = "^[a-zA-Z][a-zA-Z0-9_]*[a-zA-Z0-9]$"
 	_FieldNameRegExp  = "^[a-zA-Z][a-zA-Z0-9_]*[a-zA-Z0-9]$"
 	_KeyRegExp        = "^[a-zA-Z0-9][a-zA-Z0-9_-]*[a-zA-Z0-9]$"
+	_AttachmentRegExp = "^[a-zA-Z0-9][a-zA-Z0-9._-]*[a-zA-Z0-9]$"
 )
 
 var (
-	bucketNameRegExp = regexp.MustCompile(_BucketNameRegExp)
-	fieldNameRegExp  = regexp.MustCompile(_FieldNameRegExp)
-	keyRegExp        = regexp.MustCompile(_KeyRegExp)
+	bucketNameRegExp     = regexp.MustCompile(_BucketNameRegExp)
+	fieldNameRegExp      = regexp.MustCompile(_FieldNameRegExp)
+	keyRegExp            = regexp.MustCompile(_KeyRegExp)
+	attachmentNameRegExp = regexp.MustCompile(_AttachmentRegExp)
 )
 
 func BucketName(name string) error {
@@ -53,33 +58,393 @@ func DataType(dataType model.DataType) error {
 		return apperror.InvalidFieldType.New(dataType)
 	}
 
-	if dataType != model.StringDataType &&
-		dataType != model.NumberDataType &&
-		dataType != model.BoolDataType {
-		return apperror.InvalidFieldType.New(dataType)
+	switch dataType {
+	case model.StringDataType, model.NumberDataType, model.BoolDataType,
+		model.IntegerDataType, model.TimestampDataType, model.DateDataType, model.EnumDataType,
+		model.ObjectDataType, model.ArrayDataType, model.BinaryDataType:
+		return nil
+	}
+
+	return apperror.InvalidFieldType.New(dataType)
+}
+
+func EnumValues(field model.Field) error {
+	if field.Type != model.EnumDataType {
+		if len(field.Values) > 0 {
+			return apperror.InvalidEnumValues.New(field.Name)
+		}
+
+		return nil
+	}
+
+	if len(field.Values) == 0 {
+		return apperror.InvalidEnumValues.New(field.Name)
+	}
+
+	for i, value := range field.Values {
+		if len(value) == 0 || slices.Contains(field.Values[:i], value) {
+			return apperror.InvalidEnumValues.New(field.Name)
+		}
 	}
 
 	return nil
 }
 
-func Schema(schema []model.Field) error {
-	if len(schema) == 0 {
+// Schema checks the fields of a bucket, document buckets don't need declared fields
+func Schema(schema []model.Field, options model.BucketOptions) error {
+	if len(schema) == 0 && !options.IsDocument() {
 		return apperror.SchemaMissing.New()
 	}
 
+	var violations apperror.Violations
+
 	for _, field := range schema {
-		if err := FieldName(field.Name); err != nil {
-			return err
+		violations.Add(field.Name, schemaField(field))
+	}
+
+	return violations.Err()
+}
+
+func schemaField(field model.Field) error {
+	if err := FieldName(field.Name); err != nil {
+		return err
+	}
+
+	if err := DataType(field.Type); err != nil {
+		return err
+	}
+
+	if err := EnumValues(field); err != nil {
+		return err
+	}
+
+	if err := NestedFields(field); err != nil {
+		return err
+	}
+
+	if err := Constraints(field); err != nil {
+		return err
+	}
+
+	return Searchable(field)
+}
+
+// NestedFields checks the items of arrays and the fields of objects
+func NestedFields(field model.Field) error {
+	switch field.Type {
+	case model.ArrayDataType:
+		if field.Items == nil || len(field.Fields) > 0 {
+			return apperror.InvalidFieldDefinition.New(field.Name)
+		}
+
+		if field.Items.Default != nil {
+			return apperror.InvalidConstraint.New(model.DefaultConstraint, field.Name)
+		}
+
+		return nestedField(field.Name, *field.Items)
+	case model.ObjectDataType:
+		if len(field.Fields) == 0 || field.Items != nil {
+			return apperror.InvalidFieldDefinition.New(field.Name)
+		}
+
+		for i, nested := range field.Fields {
+			if err := FieldName(nested.Name); err != nil {
+				return err
+			}
+
+			if _, found := model.FieldByName(field.Fields[:i], nested.Name); found {
+				return apperror.FieldAlreadyExists.New(field.Name + "." + nested.Name)
+			}
+
+			if err := nestedField(field.Name+"."+nested.Name, nested); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if field.Items != nil || len(field.Fields) > 0 {
+		return apperror.InvalidFieldDefinition.New(field.Name)
+	}
+
+	return nil
+}
+
+func nestedField(path string, field model.Field) error {
+	field.Name = path
+
+	if field.Indexed || field.Searchable {
+		return apperror.InvalidFieldDefinition.New(path)
+	}
+
+	if err := DataType(field.Type); err != nil {
+		return err
+	}
+
+	if err := EnumValues(field); err != nil {
+		return err
+	}
+
+	if err := NestedFields(field); err != nil {
+		return err
+	}
+
+	return Constraints(field)
+}
+
+// Constraints checks that the constraints are supported by the field type and coherent with each other
+func Constraints(field model.Field) error {
+	for _, constraint := range field.Constraints() {
+		if !constraint.Supports(field.Type) {
+			return apperror.InvalidConstraint.New(constraint, field.Name)
+		}
+	}
+
+	if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+		return apperror.InvalidConstraint.New(model.MaxConstraint, field.Name)
+	}
+
+	if field.MinLength != nil && *field.MinLength < 0 {
+		return apperror.InvalidConstraint.New(model.MinLengthConstraint, field.Name)
+	}
+
+	if field.MaxLength != nil && (*field.MaxLength < 0 || (field.MinLength != nil && *field.MinLength > *field.MaxLength)) {
+		return apperror.InvalidConstraint.New(model.MaxLengthConstraint, field.Name)
+	}
+
+	if _, err := regexp.Compile(field.Pattern); err != nil {
+		return apperror.InvalidConstraint.WithCause(err, model.PatternConstraint, field.Name)
+	}
+
+	if field.Default != nil {
+		if err := fieldValue(field, field.Default, field.Name); err != nil {
+			return apperror.InvalidConstraint.WithCause(err, model.DefaultConstraint, field.Name)
 		}
+	}
+
+	return nil
+}
+
+func Searchable(field model.Field) error {
+	if field.Searchable && field.Type != model.StringDataType {
+		return apperror.FieldNotSearchable.New(field.Name)
+	}
 
-		if err := DataType(field.Type); err != nil {
+	return nil
+}
+
+func SchemaChange(change model.SchemaChange, schema []model.Field, options model.BucketOptions) error {
+	if change.IsEmpty() {
+		return apperror.EmptySchemaChange.New()
+	}
+
+	fieldMap := toFieldMap(schema)
+
+	for _, name := range change.Drop {
+		if _, found := fieldMap[name]; !found {
+			return apperror.UnknownField.New(name)
+		}
+
+		if indexes := options.IndexesWith(name); len(indexes) > 0 {
+			return apperror.FieldIndexed.New(name, indexes[0].Name)
+		}
+
+		delete(fieldMap, name)
+	}
+
+	for _, field := range change.Add {
+		if err := schemaField(field); err != nil {
 			return err
 		}
+
+		if _, found := fieldMap[field.Name]; found {
+			return apperror.FieldAlreadyExists.New(field.Name)
+		}
+
+		fieldMap[field.Name] = field
+	}
+
+	for _, fieldChange := range change.Alter {
+		field, found := fieldMap[fieldChange.Name]
+		if !found {
+			return apperror.UnknownField.New(fieldChange.Name)
+		}
+
+		if fieldChange.Searchable != nil {
+			field.Searchable = *fieldChange.Searchable
+			if err := Searchable(field); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(fieldMap) == 0 && !options.IsDocument() {
+		return apperror.SchemaMissing.New()
+	}
+
+	return nil
+}
+
+func Options(options model.BucketOptions, schema []model.Field) error {
+	if options.DefaultTTL < 0 {
+		return apperror.InvalidTTL.New(options.DefaultTTL)
+	}
+
+	switch options.Mode {
+	case "", model.SchemaMode, model.DocumentMode:
+	default:
+		return apperror.InvalidBucketMode.New(options.Mode)
+	}
+
+	fieldMap := toFieldMap(schema)
+	names := make(map[string]bool)
+
+	for _, index := range options.Indexes {
+		if FieldName(index.Name) != nil || names[index.Name] || len(index.Fields) == 0 {
+			return apperror.InvalidIndex.New(index.Name)
+		}
+
+		names[index.Name] = true
+
+		for i, name := range index.Fields {
+			if _, found := fieldMap[name]; !found {
+				return apperror.UnknownField.New(name)
+			}
+
+			if slices.Contains(index.Fields[:i], name) {
+				return apperror.InvalidIndex.New(index.Name)
+			}
+		}
 	}
 
 	return nil
 }
 
+// JSONSchema checks that a JSON Schema can be translated to the fields of a bucket,
+// errors identify the schema using a JSON pointer, e.g. #/properties/address
+func JSONSchema(schema model.JSONSchema, options model.BucketOptions) error {
+	var violations apperror.Violations
+
+	if !schema.SupportedDialect() {
+		violations.Add("#", apperror.InvalidJSONSchema.New("#", "unsupported $schema "+schema.Dialect))
+	}
+
+	if schema.Type != "object" {
+		violations.Add("#", apperror.InvalidJSONSchema.New("#", "the root must be an object"))
+	}
+
+	if len(options.Mode) > 0 && options.Mode != schema.Mode() {
+		violations.Add("#", apperror.InvalidJSONSchema.New("#", "additionalProperties must be true only on document buckets"))
+	}
+
+	violations.Add("#", jsonSchemaNode(schema, "#"))
+
+	return violations.Err()
+}
+
+func jsonSchemaNode(schema model.JSONSchema, path string) error {
+	var violations apperror.Violations
+
+	invalid := func(reason string) {
+		violations.Add(path, apperror.InvalidJSONSchema.New(path, reason))
+	}
+
+	for _, keyword := range schema.Unsupported {
+		violations.Add(path, apperror.UnsupportedSchemaKeyword.New(keyword, path))
+	}
+
+	root := path == "#"
+
+	if !root && len(schema.Dialect) > 0 {
+		violations.Add(path, apperror.UnsupportedSchemaKeyword.New("$schema", path))
+	}
+
+	if !root && len(schema.ID) > 0 {
+		violations.Add(path, apperror.UnsupportedSchemaKeyword.New("$id", path))
+	}
+
+	dataType := schema.DataType()
+	if len(dataType) == 0 {
+		invalid(unsupportedType(schema))
+		return violations.Err()
+	}
+
+	if schema.Type != "string" && (len(schema.Format) > 0 || len(schema.ContentEncoding) > 0 || len(schema.Enum) > 0) {
+		invalid("format, contentEncoding and enum are only supported on strings")
+	}
+
+	if dataType != model.ObjectDataType && (len(schema.Properties) > 0 || len(schema.Required) > 0 || schema.AdditionalProperties != nil) {
+		invalid("properties, required and additionalProperties are only supported on objects")
+	}
+
+	if !root && dataType == model.ObjectDataType && schema.Mode() == model.DocumentMode {
+		invalid("additionalProperties is only supported on the root")
+	}
+
+	if dataType != model.ArrayDataType && (schema.Items != nil || schema.MinItems != nil || schema.MaxItems != nil) {
+		invalid("items, minItems and maxItems are only supported on arrays")
+	}
+
+	if dataType == model.ArrayDataType && schema.Items == nil {
+		invalid("arrays must declare their items")
+	}
+
+	if (dataType == model.ArrayDataType || dataType == model.BinaryDataType) && (schema.MinLength != nil || schema.MaxLength != nil) {
+		invalid("minLength and maxLength are not supported on arrays and binary values")
+	}
+
+	for _, value := range schema.Enum {
+		if _, ok := value.(string); !ok {
+			invalid("enum values must be strings")
+			break
+		}
+	}
+
+	for _, name := range schema.Required {
+		declared := slices.ContainsFunc(schema.Properties, func(property model.JSONProperty) bool {
+			return property.Name == name
+		})
+
+		if !declared {
+			invalid("required property " + name + " is not declared")
+		}
+	}
+
+	for _, property := range schema.Properties {
+		violations.Add(path, jsonSchemaNode(property.Schema, path+"/properties/"+property.Name))
+	}
+
+	if schema.Items != nil {
+		violations.Add(path, jsonSchemaNode(*schema.Items, path+"/items"))
+	}
+
+	return violations.Err()
+}
+
+// unsupportedType describes the keywords used to find the field type of a schema without matching type
+func unsupportedType(schema model.JSONSchema) string {
+	if schema.Type == nil {
+		return "type is required"
+	}
+
+	description := fmt.Sprintf("unsupported type %v", schema.Type)
+
+	if len(schema.Format) > 0 {
+		description += " with format " + schema.Format
+	}
+
+	if len(schema.ContentEncoding) > 0 {
+		description += " with contentEncoding " + schema.ContentEncoding
+	}
+
+	if len(schema.Enum) > 0 {
+		description += " with enum"
+	}
+
+	return description
+}
+
 func Key(value string) error {
 	if len(value) == 0 || len(value) > 50 {
 		return apperror.InvalidKey.New(value)
@@ -94,18 +459,50 @@ func Key(value string) error {
 	return nil
 }
 
+// Value validates the value of a key using the mode of its bucket
+func Value(obj model.Object, schema []model.Field, options model.BucketOptions) error {
+	if options.IsDocument() {
+		return Document(obj, schema)
+	}
+
+	return Object(obj, schema)
+}
+
 func Object(obj model.Object, schema []model.Field) error {
-	fieldMap := toFieldMap(schema)
+	return object(obj, schema, "", false)
+}
 
-	for name, value := range obj {
-		field, found := fieldMap[name]
-		if !found {
-			return apperror.UnknownField.New(name)
+// Document validates the values of document buckets, only declared fields are checked against the schema
+// and undeclared fields just need a valid name
+func Document(obj model.Object, schema []model.Field) error {
+	return object(obj, schema, "", true)
+}
+
+// object validates the values of an object, nested objects and arrays are validated recursively
+// and errors identify the value using its dotted path, e.g. address.city or tags.2
+func object(obj map[string]any, schema []model.Field, prefix string, document bool) error {
+	var violations apperror.Violations
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		field, found := model.FieldByName(schema, name)
+		if !found && document {
+			violations.Add(prefix+name, FieldName(name))
+			continue
 		}
 
-		if !field.Type.ValidValue(value) {
-			return apperror.InvalidField.New(name)
+		if !found {
+			violations.Add(prefix+name, apperror.UnknownField.New(prefix+name))
+			continue
 		}
+
+		violations.Add(prefix+name, fieldValue(field, obj[name], prefix+name))
 	}
 
 	for _, field := range schema {
@@ -114,30 +511,378 @@ func Object(obj model.Object, schema []model.Field) error {
 		}
 
 		if _, found := obj[field.Name]; !found {
-			return apperror.MissingField.New(field.Name)
+			violations.Add(prefix+field.Name, apperror.MissingField.New(prefix+field.Name))
+		}
+	}
+
+	return violations.Err()
+}
+
+func fieldValue(field model.Field, value any, path string) error {
+	switch field.Type {
+	case model.ObjectDataType:
+		if nested, ok := value.(map[string]any); ok {
+			return object(nested, field.Fields, path+".", false)
+		}
+	case model.ArrayDataType:
+		if list, ok := value.([]any); ok && field.Items != nil {
+			var violations apperror.Violations
+
+			for i, item := range list {
+				itemPath := path + "." + strconv.Itoa(i)
+				violations.Add(itemPath, fieldValue(*field.Items, item, itemPath))
+			}
+
+			if err := violations.Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !field.ValidValue(value) {
+		return apperror.InvalidField.New(path)
+	}
+
+	if constraint, violated := field.Violation(value); violated {
+		return apperror.ConstraintViolation.New(path, constraint)
+	}
+
+	return nil
+}
+
+func Operation(operation model.Operation, schema []model.Field, options model.BucketOptions) error {
+	if err := Key(operation.Key); err != nil {
+		return err
+	}
+
+	switch operation.Type {
+	case model.SetOperation:
+		if operation.TTL < 0 {
+			return apperror.InvalidTTL.New(operation.TTL)
 		}
+
+		return Value(operation.Value, schema, options)
+	case model.DeleteOperation:
+		return nil
+	case model.CheckOperation:
+		return Condition(operation.Key, operation.Condition, schema)
+	}
+
+	return apperror.InvalidOperation.New(operation.Type)
+}
+
+func Condition(key string, condition *model.Condition, schema []model.Field) error {
+	if condition == nil {
+		return apperror.InvalidCondition.New(key)
+	}
+
+	if condition.Exists != nil {
+		if len(condition.Field) > 0 {
+			return apperror.InvalidCondition.New(key)
+		}
+
+		return nil
+	}
+
+	if len(condition.Field) == 0 {
+		return apperror.InvalidCondition.New(key)
+	}
+
+	field, found := toFieldMap(schema)[condition.Field]
+	if !found {
+		return apperror.UnknownField.New(condition.Field)
+	}
+
+	if condition.Equals != nil && !field.ValidValue(condition.Equals) {
+		return apperror.InvalidField.New(condition.Field)
 	}
 
 	return nil
 }
 
 func Criteria(criteria url.Values, schema []model.Field) error {
+	var violations apperror.Violations
+
+	parameters := make([]string, 0, len(criteria))
+	for parameter := range criteria {
+		parameters = append(parameters, parameter)
+	}
+
+	slices.Sort(parameters)
+
+	for _, parameter := range parameters {
+		violations.Add(parameter, criterion(parameter, criteria[parameter], schema))
+	}
+
+	return violations.Err()
+}
+
+func criterion(parameter string, values []string, schema []model.Field) error {
+	name, operator, ok := model.ParseParameter(parameter)
+	if !ok {
+		return apperror.InvalidCriteria.New(parameter)
+	}
+
+	field, found := model.FieldByPath(schema, name)
+	if !found {
+		return apperror.UnknownField.New(name)
+	}
+
+	if !operator.Supports(field.Type) || !containsScalar(operator, field) {
+		return apperror.InvalidCriteria.New(parameter)
+	}
+
+	for _, value := range values {
+		if _, err := operator.Convert(field, value); err != nil {
+			return apperror.InvalidCriteria.WithCause(err, parameter+"="+value)
+		}
+	}
+
+	return nil
+}
+
+func Query(parameters url.Values, schema []model.Field) error {
+	if err := Criteria(model.CriteriaParameters(parameters), schema); err != nil {
+		return err
+	}
+
 	fieldMap := toFieldMap(schema)
 
-	for name := range criteria {
+	for _, name := range model.ParseFields(parameters) {
 		if _, found := fieldMap[name]; !found {
 			return apperror.UnknownField.New(name)
 		}
 	}
 
+	for _, sort := range model.ParseSort(parameters) {
+		if len(sort.Field) == 0 {
+			return apperror.InvalidSort.New(parameters.Get(model.SortParameter))
+		}
+
+		field, found := fieldMap[sort.Field]
+		if !found {
+			return apperror.UnknownField.New(sort.Field)
+		}
+
+		if field.Type == model.BinaryDataType {
+			return apperror.InvalidSort.New(parameters.Get(model.SortParameter))
+		}
+	}
+
+	if parameters.Has(model.SearchParameter) && len(model.ParseSearch(parameters)) == 0 {
+		return apperror.InvalidCriteria.New(model.SearchParameter + "=" + parameters.Get(model.SearchParameter))
+	}
+
+	limit, err := model.ParseLimit(parameters)
+	if err != nil || limit < 0 || limit > model.MaxLimit || (limit == 0 && model.Paginated(parameters)) {
+		return apperror.InvalidLimit.New(parameters.Get(model.LimitParameter), model.MaxLimit)
+	}
+
+	cursor, err := model.ParseCursor(parameters)
+	if err != nil {
+		return apperror.InvalidCursor.WithCause(err, parameters.Get(model.CursorParameter))
+	}
+
+	if cursor != nil && !cursor.Matches(model.SortOrder(parameters)) {
+		return apperror.InvalidCursor.New(parameters.Get(model.CursorParameter))
+	}
+
 	return nil
 }
 
+func Aggregation(parameters url.Values, schema []model.Field) error {
+	if err := Criteria(model.AggregationCriteria(parameters), schema); err != nil {
+		return err
+	}
+
+	fieldMap := toFieldMap(schema)
+
+	for _, aggregate := range model.ParseAggregates(parameters) {
+		field, found := fieldMap[aggregate.Field]
+		if !found {
+			return apperror.UnknownField.New(aggregate.Field)
+		}
+
+		if !aggregate.Function.Supports(field.Type) {
+			return apperror.InvalidAggregate.New(string(aggregate.Function) + "=" + aggregate.Field)
+		}
+	}
+
+	for _, name := range model.ParseGroupBy(parameters) {
+		if _, found := fieldMap[name]; !found {
+			return apperror.UnknownField.New(name)
+		}
+	}
+
+	return nil
+}
+
+func SearchRequest(request model.SearchRequest, schema []model.Field) error {
+	fieldMap := toFieldMap(schema)
+
+	if request.Filter != nil {
+		if err := filter(request.Filter, schema); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range request.Fields {
+		if _, found := fieldMap[name]; !found {
+			return apperror.UnknownField.New(name)
+		}
+	}
+
+	sort := request.SortOrder()
+
+	for _, field := range sort {
+		if len(field.Field) == 0 {
+			return apperror.InvalidSort.New(request.Sort)
+		}
+
+		sortField, found := fieldMap[field.Field]
+		if !found {
+			return apperror.UnknownField.New(field.Field)
+		}
+
+		if sortField.Type == model.BinaryDataType {
+			return apperror.InvalidSort.New(request.Sort)
+		}
+	}
+
+	if request.Limit < 0 || request.Limit > model.MaxLimit {
+		return apperror.InvalidLimit.New(request.Limit, model.MaxLimit)
+	}
+
+	if len(request.Cursor) > 0 {
+		cursor, err := model.DecodeCursor(request.Cursor)
+		if err != nil {
+			return apperror.InvalidCursor.WithCause(err, request.Cursor)
+		}
+
+		if !cursor.Matches(sort) {
+			return apperror.InvalidCursor.New(request.Cursor)
+		}
+	}
+
+	switch request.Return {
+	case "", model.KeysResult, model.ObjectsResult:
+		return nil
+	}
+
+	return apperror.InvalidResultType.New(request.Return)
+}
+
+func filter(node *model.Filter, schema []model.Field) error {
+	kinds := 0
+	for _, present := range []bool{node.And != nil, node.Or != nil, node.Not != nil, len(node.Field) > 0} {
+		if present {
+			kinds++
+		}
+	}
+
+	if kinds != 1 {
+		return apperror.InvalidFilter.New("node, it must have one of and, or, not or field")
+	}
+
+	switch {
+	case node.And != nil:
+		return filterList("and", node.And, schema)
+	case node.Or != nil:
+		return filterList("or", node.Or, schema)
+	case node.Not != nil:
+		return filter(node.Not, schema)
+	}
+
+	return comparison(node, schema)
+}
+
+func filterList(operator string, nodes []model.Filter, schema []model.Field) error {
+	if len(nodes) == 0 {
+		return apperror.InvalidFilter.New(operator + " without conditions")
+	}
+
+	for i := range nodes {
+		if err := filter(&nodes[i], schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func comparison(node *model.Filter, schema []model.Field) error {
+	field, found := model.FieldByPath(schema, node.Field)
+	if !found {
+		return apperror.UnknownField.New(node.Field)
+	}
+
+	operator := node.Comparison()
+	description := node.Field + "[" + string(operator) + "]"
+
+	if !operator.Supports(field.Type) || !containsScalar(operator, field) {
+		return apperror.InvalidFilter.New(description)
+	}
+
+	if operator == model.ContainsOperator {
+		field = *field.Items
+	}
+
+	switch operator {
+	case model.NullOperator, model.ExistsOperator:
+		if _, ok := node.Value.(bool); !ok {
+			return apperror.InvalidFilter.New(description)
+		}
+	case model.InOperator:
+		values, ok := node.Value.([]any)
+		if !ok || len(values) == 0 {
+			return apperror.InvalidFilter.New(description)
+		}
+
+		for _, value := range values {
+			if value == nil || !field.ValidValue(value) {
+				return apperror.InvalidFilter.New(description)
+			}
+		}
+	default:
+		if node.Value == nil || !field.ValidValue(node.Value) {
+			return apperror.InvalidFilter.New(description)
+		}
+	}
+
+	return nil
+}
+
+// containsScalar checks that contains is only used on arrays of scalar values
+func containsScalar(operator model.Operator, field model.Field) bool {
+	if operator != model.ContainsOperator {
+		return true
+	}
+
+	return field.Items != nil && !field.Items.Type.IsJSON()
+}
+
+// toFieldMap maps the declared fields, the document field is only used to find undeclared paths
 func toFieldMap(schema []model.Field) map[string]model.Field {
 	fieldMap := make(map[string]model.Field)
 	for _, field := range schema {
-		fieldMap[field.Name] = field
+		if field.Name != model.DocumentField {
+			fieldMap[field.Name] = field
+		}
 	}
 
 	return fieldMap
 }
+
+func AttachmentName(name string) error {
+	if len(name) == 0 || len(name) > 100 {
+		return apperror.InvalidAttachmentName.New(name)
+	}
+
+	matched := attachmentNameRegExp.MatchString(name)
+
+	if !matched {
+		return apperror.InvalidAttachmentName.New(name)
+	}
+
+	return nil
+}